@@ -5,9 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,14 +20,21 @@ import (
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/dao/config"
 	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/font"
 	"curly-succotash/backend/pkg/logger"
+	"curly-succotash/backend/pkg/metrics"
 	"curly-succotash/backend/pkg/setting"
+	"curly-succotash/backend/pkg/tracer"
 	"curly-succotash/backend/routers"
 )
 
 var (
-	runMode string
-	cfg     string
+	runMode   string
+	cfg       string
+	settings  *setting.Setting
+	checkOnly bool
+
+	shutdownTracer func(context.Context) error
 )
 
 func init() {
@@ -34,6 +42,12 @@ func init() {
 	if err != nil {
 		log.Fatalf("init.setupFlag err: %v", err)
 	}
+	if checkOnly {
+		if !checkConfig() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	err = setupSetting()
 	if err != nil {
 		log.Fatalf("init.setupSetting err: %v", err)
@@ -42,6 +56,15 @@ func init() {
 	if err != nil {
 		log.Fatalf("init.setupLogger err: %v", err)
 	}
+	warnUnresolved()
+	err = setupTracer()
+	if err != nil {
+		log.Fatalf("init.setupTracer err: %v", err)
+	}
+	err = setupFont()
+	if err != nil {
+		log.Fatalf("init.setupFont err: %v", err)
+	}
 
 	err = setupDBEngine()
 	if err != nil {
@@ -78,27 +101,20 @@ func main() {
 	defer cancel()
 
 	// Set Gin mode
-	gin.SetMode(global.AppSetting.RunMode)
+	gin.SetMode(global.AppSetting.Load().RunMode)
 
 	// Initialize router
 	router := routers.NewRouter()
 
-	// Create HTTP server
-	s := &http.Server{
-		Addr:           fmt.Sprintf(":%s", global.ServerSetting.HttpPort),
-		Handler:        router,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+	// Start HTTP server
+	s := newServer(router)
+	if err := s.Start(ctx); err != nil {
+		global.Logger.Fatalf(ctx, "Failed to start server: %v", err)
+		os.Exit(1)
 	}
 
-	// Start server in a goroutine
-	go func() {
-		global.Logger.Infof(ctx, "Starting server on port %d", global.ServerSetting.HttpPort)
-		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			global.Logger.Fatalf(ctx, "Failed to start server: %v", err)
-		}
-	}()
+	// Apply configuration changes to the running components
+	setupReload(ctx, s)
 
 	// Setup signal handling
 	stopChannel := make(chan os.Signal, 1)
@@ -117,53 +133,125 @@ func main() {
 		global.Logger.Errorf(ctx, "Server shutdown failed: %v", err)
 	}
 
+	// Flush pending spans
+	if err := shutdownTracer(shutdownCtx); err != nil {
+		global.Logger.Errorf(ctx, "Tracer shutdown failed: %v", err)
+	}
+
 	global.Logger.Infof(ctx, "Server stopped")
 }
 
 func setupFlag() error {
-	flag.StringVar(&runMode, "mode", "", "running level (info, debug)")
+	flag.StringVar(&runMode, "mode", "", "run mode overriding App.RunMode (debug, release or test; info is release)")
 	flag.StringVar(&cfg, "config", "etc/", "assgin the path of config file")
+	flag.BoolVar(&checkOnly, "check-config", false, "validate the config, report unresolved placeholders and exit")
 	flag.Parse()
 
 	return nil
 }
 
+// configSections maps the config.yaml sections to the globals they are read into
+var configSections = []struct {
+	name string
+	v    interface{}
+}{
+	{"App", &global.AppSetting},
+	{"Database", &global.DatabaseSetting},
+	{"Server", &global.ServerSetting},
+	{"StoragePath", &global.StoragePathSetting},
+	{"AI", &global.AISetting},
+	{"Font", &global.FontSetting},
+	{"JWT", &global.JWTSetting},
+	{"Limiter", &global.LimiterSetting},
+	{"Tracing", &global.TracingSetting},
+}
+
 func setupSetting() error {
 	s, err := setting.NewSetting(strings.Split(cfg, ",")...)
 	if err != nil {
 		return err
 	}
-	err = s.ReadSection("App", &global.AppSetting)
-	if err != nil {
+	settings = s
+	if err := applyRunMode(s); err != nil {
 		return err
 	}
-	err = s.ReadSection("Database", &global.DatabaseSetting)
-	if err != nil {
-		return err
+	for _, section := range configSections {
+		err = s.ReadSection(section.name, section.v)
+		if err != nil {
+			return err
+		}
 	}
-	err = s.ReadSection("Server", &global.ServerSetting)
-	if err != nil {
-		return err
+
+	return global.JWTSetting.Load().CheckSecret(global.AppSetting.Load().RunMode)
+}
+
+// applyRunMode lets the -mode flag override App.RunMode, also on reloads.
+// "info" is accepted as release, which logs from the info level by default.
+func applyRunMode(s *setting.Setting) error {
+	mode := runMode
+	switch mode {
+	case "":
+		return nil
+	case "info":
+		mode = gin.ReleaseMode
+	default:
+		if !slices.Contains(setting.RunModes, mode) {
+			return fmt.Errorf("unknown run mode %q, want one of %v", mode, setting.RunModes)
+		}
 	}
-	err = s.ReadSection("StoragePath", &global.StoragePathSetting)
-	if err != nil {
-		return err
+
+	return s.Override("App.RunMode", mode)
+}
+
+// warnUnresolved logs the config placeholders whose environment variable is
+// not set
+func warnUnresolved() {
+	for _, p := range settings.Unresolved() {
+		global.Logger.Warnf(context.Background(), "Unresolved config placeholder %s", p)
 	}
-	err = s.ReadSection("AI", &global.AISetting)
+}
+
+// checkConfig validates every config section and reports placeholders whose
+// environment variable is not set. It returns false when the config is
+// unusable.
+func checkConfig() bool {
+	s, err := setting.NewSetting(strings.Split(cfg, ",")...)
 	if err != nil {
-		return err
+		fmt.Printf("failed to load config: %v\n", err)
+		return false
 	}
-
-	// TODO: run mode
-
-	return nil
+	ok := true
+	if err := applyRunMode(s); err != nil {
+		fmt.Println(err)
+		ok = false
+	}
+	for _, p := range s.Unresolved() {
+		fmt.Printf("unresolved placeholder %s\n", p)
+		ok = false
+	}
+	for _, section := range configSections {
+		if err := s.ReadSection(section.name, section.v); err != nil {
+			fmt.Println(err)
+			ok = false
+		}
+	}
+	if ok {
+		if err := global.JWTSetting.Load().CheckSecret(global.AppSetting.Load().RunMode); err != nil {
+			fmt.Println(err)
+			ok = false
+		}
+	}
+	if ok {
+		fmt.Println("config OK")
+	}
+	return ok
 }
 
 // DB mirgration
 func updateDB() error {
 	var err error
 	updateDBSetup := &config.StorageSetup{}
-	err = updateDBSetup.NewDBEngine(global.DatabaseSetting)
+	err = updateDBSetup.NewDBEngine(global.DatabaseSetting.Load())
 	if err != nil {
 		return err
 	}
@@ -171,13 +259,22 @@ func updateDB() error {
 		log.Fatalf("open storage connection failed: %v", err)
 		return err
 	}
+	global.StorageEngine = updateDBSetup.Instance
 
 	return nil
 }
 
 func setupDBEngine() error {
 	var err error
-	global.DBEngine, err = model.NewDBEngine(global.DatabaseSetting)
+	global.DBEngine, err = model.NewDBEngine(global.DatabaseSetting.Load())
+	if err != nil {
+		return err
+	}
+	sqlDB, err := global.DBEngine.DB()
+	if err != nil {
+		return err
+	}
+	err = metrics.RegisterDB(global.DatabaseSetting.Load().DBType, sqlDB)
 	if err != nil {
 		return err
 	}
@@ -185,13 +282,110 @@ func setupDBEngine() error {
 	return nil
 }
 
+// setupLogger logs JSON to the rotating log file and to stdout in the
+// App.LogConsole format, pretty by default in debug mode
 func setupLogger() error {
-	global.Logger = logger.NewLogger(&lumberjack.Logger{
-		Filename:  global.AppSetting.LogSavePath + "/" + global.AppSetting.LogFileName + global.AppSetting.LogFileExt,
+	app := global.AppSetting.Load()
+	handlers := []slog.Handler{logger.NewJSONHandler(&lumberjack.Logger{
+		Filename:  app.LogSavePath + "/" + app.LogFileName + app.LogFileExt,
 		MaxSize:   600,
 		MaxAge:    10,
 		LocalTime: true,
-	}, "", log.LstdFlags).WithCaller(2)
+	})}
+	console := app.LogConsole
+	if console == "" {
+		console = "json"
+		if app.RunMode == gin.DebugMode {
+			console = "pretty"
+		}
+	}
+	switch console {
+	case "pretty":
+		handlers = append(handlers, logger.NewPrettyHandler(os.Stdout))
+	case "json":
+		handlers = append(handlers, logger.NewJSONHandler(os.Stdout))
+	}
+	global.Logger = logger.NewLogger(handlers...)
+	setLogSecrets()
+
+	return setLogLevel()
+}
+
+// setLogSecrets redacts the AI API key, the JWT secret and the database
+// password from the logs. The shipped JWT secret is public and equals the
+// project name, and values equal to the JWT issuer or the tracing service
+// name are logged on purpose, so redacting them would only mangle the logs.
+func setLogSecrets() {
+	jwt := global.JWTSetting.Load()
+	public := map[string]bool{
+		setting.DefaultJWTSecret:                 true,
+		jwt.Issuer:                               true,
+		global.TracingSetting.Load().ServiceName: true,
+	}
+	apiKey, _, _ := setting.LookupEnv(global.AISetting.Load().APIKey)
+	var secrets []string
+	for _, v := range []string{apiKey, jwt.Secret, global.DatabaseSetting.Load().Password} {
+		if !public[v] {
+			secrets = append(secrets, v)
+		}
+	}
+	logger.SetSecrets(secrets...)
+}
+
+// setLogLevel applies App.LogLevel and App.LogPackages. Without LogLevel the
+// debug run mode logs everything and the other modes log from info.
+func setLogLevel() error {
+	app := global.AppSetting.Load()
+	levels := logger.Levels{Default: logger.LevelInfo, Packages: map[string]logger.Level{}}
+	if app.RunMode == gin.DebugMode {
+		levels.Default = logger.LevelDebug
+	}
+	var err error
+	if app.LogLevel != "" {
+		if levels.Default, err = logger.ParseLevel(app.LogLevel); err != nil {
+			return err
+		}
+	}
+	for pkg, level := range app.LogPackages {
+		if levels.Packages[pkg], err = logger.ParseLevel(level); err != nil {
+			return err
+		}
+	}
+	global.Logger.SetLevels(levels)
+
+	return nil
+}
+
+// setupTracer installs the OpenTelemetry tracer provider and the exporter
+// configured in the Tracing section
+func setupTracer() error {
+	var err error
+	shutdownTracer, err = tracer.Setup(context.Background(), global.TracingSetting.Load())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setupFont loads the TrueType fonts embedded into exported PDFs. Missing
+// fonts are not fatal: their scripts use the fonts that exist, and exports
+// fall back to the core Arial font when there are none.
+func setupFont() error {
+	s := global.FontSetting.Load()
+	if s == nil {
+		return nil
+	}
+	fonts, err := font.NewRegistry(global.StoragePathSetting.Load().FontFolder, map[font.Script]string{
+		font.ScriptLatin:    s.Latin,
+		font.ScriptHan:      s.Han,
+		font.ScriptJapanese: s.Japanese,
+		font.ScriptEmoji:    s.Emoji,
+	})
+	if err != nil {
+		global.Logger.Warnf(context.Background(), "Skipped missing fonts, their scripts use the other fonts: %s", err)
+	}
+	global.Fonts = fonts
 
 	return nil
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupReload subscribes the running components to configuration changes.
+// Reloads are validated first: a config failing validation is rejected as a
+// whole and the previous values stay in place.
+func setupReload(ctx context.Context, s *server) {
+	settings.OnReloadError(func(err error) {
+		global.Logger.Errorf(ctx, "Rejected configuration change: %s", err)
+	})
+
+	settings.Subscribe(func(changes []setting.Change) {
+		for _, c := range changes {
+			global.Logger.Infof(ctx, "Configuration changed: %s", c)
+		}
+		warnUnresolved()
+		setLogSecrets()
+	})
+
+	settings.Subscribe(func(changes []setting.Change) {
+		if err := setLogLevel(); err != nil {
+			global.Logger.Errorf(ctx, "Failed to apply log level: %s", err)
+		}
+		for _, c := range changes {
+			if c.Section != "App" {
+				continue
+			}
+			switch c.Key {
+			case "RunMode":
+				app := global.AppSetting.Load()
+				gin.SetMode(app.RunMode)
+				global.Logger.Warnf(ctx, "App.RunMode: Swagger and request logging change on restart")
+				if err := global.JWTSetting.Load().CheckSecret(app.RunMode); err != nil {
+					global.Logger.Errorf(ctx, "%s; the server will refuse to restart", err)
+				}
+			case "LogConsole", "LogSavePath", "LogFileName", "LogFileExt":
+				global.Logger.Warnf(ctx, "App.%s changes on restart", c.Key)
+			}
+		}
+	}, "App")
+
+	settings.Subscribe(func([]setting.Change) {
+		ai.Reset()
+	}, "AI")
+
+	settings.Subscribe(func(changes []setting.Change) {
+		sqlDB, err := global.DBEngine.DB()
+		if err != nil {
+			global.Logger.Errorf(ctx, "Failed to resize database pool: %s", err)
+			return
+		}
+		s := global.DatabaseSetting.Load()
+		sqlDB.SetMaxIdleConns(s.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(s.MaxOpenConns)
+		for _, c := range changes {
+			if c.Section == "Database" && c.Key != "MaxIdleConns" && c.Key != "MaxOpenConns" {
+				global.Logger.Warnf(ctx, "Database.%s changes on restart", c.Key)
+			}
+		}
+	}, "Database")
+
+	settings.Subscribe(func(changes []setting.Change) {
+		for _, c := range changes {
+			if c.Section == "Limiter" && c.Key != "DailyGenerations" {
+				global.Logger.Warnf(ctx, "Limiter.%s changes on restart", c.Key)
+			}
+		}
+	}, "Limiter")
+
+	settings.Subscribe(func(changes []setting.Change) {
+		for _, c := range changes {
+			if c.Section == "Tracing" {
+				global.Logger.Warnf(ctx, "Tracing.%s changes on restart", c.Key)
+			}
+		}
+	}, "Tracing")
+
+	settings.Subscribe(func(changes []setting.Change) {
+		for _, c := range changes {
+			if c.Section == "Server" && c.Key == "TrustedProxies" {
+				global.Logger.Warnf(ctx, "Server.TrustedProxies changes on restart")
+			}
+		}
+		if err := s.Restart(ctx); err != nil {
+			global.Logger.Errorf(ctx, "Failed to restart server with the new Server settings: %s", err)
+		}
+	}, "Server")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/setting"
+)
+
+// server serves the router with the timeouts and port of the Server section.
+// When the section changes, Restart starts a new http.Server next to the
+// running one, on a SO_REUSEPORT listener, and then gracefully shuts the old
+// one down, so no connection is refused during the switch.
+type server struct {
+	handler http.Handler
+
+	mu      sync.Mutex
+	current *http.Server
+}
+
+func newServer(handler http.Handler) *server {
+	return &server{handler: handler}
+}
+
+// Start listens on the configured port and serves in the background
+func (s *server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	srv, err := s.listen(ctx, global.ServerSetting.Load())
+	if err != nil {
+		return err
+	}
+	s.current = srv
+	return nil
+}
+
+// Restart replaces the running server with one using the current Server
+// section. The old server keeps running when the new one cannot listen.
+func (s *server) Restart(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	srv, err := s.listen(ctx, global.ServerSetting.Load())
+	if err != nil {
+		return err
+	}
+	old := s.current
+	s.current = srv
+	if old != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), old.WriteTimeout)
+		defer cancel()
+		return old.Shutdown(shutdownCtx)
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the running server
+func (s *server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return nil
+	}
+	return s.current.Shutdown(ctx)
+}
+
+func (s *server) listen(ctx context.Context, cfg *setting.ServerSettingS) (*http.Server, error) {
+	lc := net.ListenConfig{Control: reusePort}
+	ln, err := lc.Listen(ctx, "tcp", ":"+cfg.HttpPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %s: %s", cfg.HttpPort, err)
+	}
+	srv := &http.Server{
+		Addr:           ln.Addr().String(),
+		Handler:        s.handler,
+		ReadTimeout:    cfg.ReadTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		MaxHeaderBytes: 1 << 20,
+	}
+	go func() {
+		global.Logger.Infof(ctx, "Serving on port %s (read timeout %s, write timeout %s)", cfg.HttpPort, cfg.ReadTimeout, cfg.WriteTimeout)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			global.Logger.Errorf(ctx, "Server on port %s failed: %v", cfg.HttpPort, err)
+		}
+	}()
+	return srv, nil
+}
+
+// reusePort lets a restarted server bind the port of the running one
+func reusePort(network, address string, c syscall.RawConn) error {
+	var serr error
+	err := c.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
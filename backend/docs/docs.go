@@ -159,7 +159,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/games/{id}/pdf": {
+        "/api/v1/generate-pdf/{id}": {
             "get": {
                 "description": "Generates a PDF file containing the board game's details and its cards, and returns the PDF file.",
                 "produces": [
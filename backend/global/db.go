@@ -1,7 +1,12 @@
 package global
 
-import "gorm.io/gorm"
+import (
+	"curly-succotash/backend/interfaces"
+
+	"gorm.io/gorm"
+)
 
 var (
-	DBEngine *gorm.DB
+	DBEngine      *gorm.DB
+	StorageEngine interfaces.StorageEngine
 )
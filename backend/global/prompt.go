@@ -3,7 +3,7 @@ package global
 const (
 	StoryPromptTemplate = "Generate a 100-word D&D-style fantasy story background for a board game. Include a setting, a central artifact, and a looming threat. Theme: %s, with json format: {\"story_background\": \"<story>\"}"
 
-	RolePrompt = `Generate %s D&D-style characters for a board game based on story background: %s. Return a JSON object with:
+	RolePrompt = `Generate %d D&D-style characters for a board game based on story background: %s. Return a JSON object with:
 		- "name": string (e.g., "Aragorn")
 		- "description": string (50-word background, include profession like Warrior/Mage and attributes: Strength, Dexterity, Wisdom, range 1-5)
 		- "effect": string (1-2 skills, e.g., "Fireball: 3 MP, D6+2 damage; Heal: 2 MP, restore 5 HP")
@@ -14,7 +14,7 @@ const (
 		"effect": "Sword Strike: D20+4 ≥ 15, D6+3 damage"
 		}`
 
-	EventPrompt = `Generate %s D&D-style board game event cards based on story background: %s. Return a JSON object with:
+	EventPrompt = `Generate %d D&D-style board game event cards based on story background: %s. Return a JSON object with:
 		- "name": string (e.g., "Dragon Attack")
 		- "description": string (50-word description tied to the background)
 		- "effect": string (e.g., "Combat: HP 10, Attack D6+1" or "Plot: Gain 1 Plot Point")
@@ -25,4 +25,14 @@ const (
 		"description": "Combat event: A fire-breathing dragon assaults the village, demanding tribute. Heroes must fight to protect the innocent.",
 		"effect": "Combat: HP 10, Attack D6+1"
 		}`
+
+	TransformStoryPrompt = `Rewrite the following D&D-style board game story background according to the instruction, keeping roughly the same length. Instruction: %s. Story background: %s. Return json format: {"story_background": "<story>"}`
+
+	TransformCardsPrompt = `Rewrite the following D&D-style board game cards according to the instruction. Instruction: %s. Story background: %s. Cards (JSON array): %s.
+		Return a JSON array with exactly one object per input card, in the same order, each with:
+		- "id": number (copied unchanged from the input card)
+		- "name": string
+		- "description": string (about 50 words)
+		- "effect": string (keep D20/D6 mechanics, e.g., "Combat: HP 10, Attack D6+1")
+		Do not change the card type.`
 )
@@ -1,15 +1,25 @@
 package global
 
 import (
+	"sync/atomic"
+
+	"curly-succotash/backend/pkg/font"
 	"curly-succotash/backend/pkg/logger"
 	"curly-succotash/backend/pkg/setting"
 )
 
+// The settings are swapped as a whole when the config file changes, so
+// Load them once per use rather than keeping the pointer
 var (
-	AppSetting         *setting.AppSettingS
-	DatabaseSetting    *setting.DatabaseSettingS
+	AppSetting         atomic.Pointer[setting.AppSettingS]
+	DatabaseSetting    atomic.Pointer[setting.DatabaseSettingS]
 	Logger             *logger.Logger
-	ServerSetting      *setting.ServerSettingS
-	StoragePathSetting *setting.StoragePathSettingS
-	AISetting          *setting.AISettingS
+	ServerSetting      atomic.Pointer[setting.ServerSettingS]
+	StoragePathSetting atomic.Pointer[setting.StoragePathSettingS]
+	AISetting          atomic.Pointer[setting.AISettingS]
+	FontSetting        atomic.Pointer[setting.FontSettingS]
+	JWTSetting         atomic.Pointer[setting.JWTSettingS]
+	LimiterSetting     atomic.Pointer[setting.LimiterSettingS]
+	TracingSetting     atomic.Pointer[setting.TracingSettingS]
+	Fonts              *font.Registry
 )
@@ -1,5 +1,7 @@
 package interfaces
 
+import "context"
+
 // StorageEngine defines methods of underlying storage
 type StorageEngine interface {
 	// Open setup and create underlying database connection pool and related resources.
@@ -7,4 +9,7 @@ type StorageEngine interface {
 
 	// Close release underlying database connection pool and related resources.
 	Close() (err error)
+
+	// Ping verifies the database connection is alive.
+	Ping(ctx context.Context) (err error)
 }
@@ -3,68 +3,160 @@ package ai
 import (
 	"context"
 	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/metrics"
+	"curly-succotash/backend/pkg/setting"
+	"curly-succotash/backend/pkg/tracer"
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
 // GeminiClient handles API calls to Google Gemini
 type GeminiClient struct {
+	client     *genai.Client
+	model      string
+	config     *genai.GenerateContentConfig
+	maxRetries int
+}
+
+// shared is the API client reused by GeminiClients, created for apiKey
+var shared struct {
+	sync.Mutex
 	client *genai.Client
-	model  string
-	config *genai.GenerateContentConfig
+	apiKey string
 }
 
-// NewGeminiClient creates a new Gemini client
+// NewGeminiClient creates a Gemini client using the current AI settings.
+// Clients keep the settings they were created with, so a reload only
+// affects later clients.
 func NewGeminiClient() (*GeminiClient, error) {
-	apiKey := os.Getenv(global.AISetting.APIKey)
+	s := *global.AISetting.Load()
+	apiKey, _, err := setting.LookupEnv(s.APIKey)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY not set")
+		return nil, fmt.Errorf("%s not set", s.APIKey)
 	}
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	client, err := sharedClient(apiKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %s", err)
+		return nil, err
 	}
 
 	return &GeminiClient{
-		client: client,
-		model:  global.AISetting.Model,
+		client:     client,
+		model:      s.Model,
+		maxRetries: s.MaxRetries,
 		config: &genai.GenerateContentConfig{
-			Temperature:      &global.AISetting.Temperature,
-			TopP:             &global.AISetting.TopP,
-			TopK:             &global.AISetting.TopK,
-			MaxOutputTokens:  global.AISetting.MaxOutputTokens,
+			Temperature:      &s.Temperature,
+			TopP:             &s.TopP,
+			TopK:             &s.TopK,
+			MaxOutputTokens:  s.MaxOutputTokens,
 			ResponseMIMEType: "application/json",
 		},
 	}, nil
 }
 
-// GenerateContent generates content using Gemini API
-func (c *GeminiClient) GenerateContent(prompt string) (string, error) {
-	ctx := context.Background()
-	result, err := c.client.Models.GenerateContent(
-		ctx,
-		c.model,
-		genai.Text(prompt),
-		c.config,
+// sharedClient returns the API client for apiKey, replacing the shared
+// client when the key changed
+func sharedClient(apiKey string) (*genai.Client, error) {
+	shared.Lock()
+	defer shared.Unlock()
+	if shared.client != nil && shared.apiKey == apiKey {
+		return shared.client, nil
+	}
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %s", err)
+	}
+	shared.client, shared.apiKey = client, apiKey
+	return client, nil
+}
+
+// Reset drops the shared API client so the next GeminiClient is created
+// from scratch, e.g. after the AI settings changed
+func Reset() {
+	shared.Lock()
+	defer shared.Unlock()
+	shared.client, shared.apiKey = nil, ""
+}
+
+// GenerateContent generates content using Gemini API. Each call runs in its
+// own span, which is forwarded to the API in the traceparent header.
+// Transient API errors are retried up to AI.MaxRetries times with backoff.
+func (c *GeminiClient) GenerateContent(ctx context.Context, prompt string) (text string, err error) {
+	ctx, span := tracer.Start(ctx, "gemini.generate_content", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("gen_ai.system", "gemini"),
+		attribute.String("gen_ai.request.model", c.model),
+		attribute.Int("gen_ai.prompt.length", len(prompt)),
 	)
+	defer func() {
+		metrics.LLMCalls.WithLabelValues(c.model, metrics.Outcome(err)).Inc()
+		tracer.End(span, err)
+	}()
+
+	config := *c.config
+	config.HTTPOptions = &genai.HTTPOptions{Headers: http.Header{}}
+	tracer.Inject(ctx, config.HTTPOptions.Headers)
+	var result *genai.GenerateContentResponse
+	for attempt := 0; ; attempt++ {
+		result, err = c.client.Models.GenerateContent(
+			ctx,
+			c.model,
+			genai.Text(prompt),
+			&config,
+		)
+		if err == nil || attempt >= c.maxRetries || !retryable(err) {
+			break
+		}
+		metrics.LLMRetries.WithLabelValues(c.model).Inc()
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed to generate content: %s", ctx.Err())
+		case <-time.After(time.Second << attempt):
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate content: %s", err)
 	}
+	if usage := result.UsageMetadata; usage != nil {
+		span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", int(usage.PromptTokenCount)),
+			attribute.Int("gen_ai.usage.output_tokens", int(usage.CandidatesTokenCount)),
+		)
+		metrics.LLMTokens.WithLabelValues(c.model, "prompt").Add(float64(usage.PromptTokenCount))
+		metrics.LLMTokens.WithLabelValues(c.model, "output").Add(float64(usage.CandidatesTokenCount))
+		addUsage(ctx, Call{Model: c.model, PromptTokens: int64(usage.PromptTokenCount), OutputTokens: int64(usage.CandidatesTokenCount)})
+	}
 
-	text := result.Text()
+	text = result.Text()
+	span.SetAttributes(attribute.Int("gen_ai.response.length", len(text)))
 	time.Sleep(500 * time.Millisecond)
 
 	return text, nil
 }
 
+// retryable reports whether err is a rate limit or server error of the API
+func retryable(err error) bool {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	return false
+}
+
 // Close is a no-op because genai.Client does not require closing resources
 func (c *GeminiClient) Close() error {
 	return nil
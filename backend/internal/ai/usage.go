@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// Call is the token usage of a GenerateContent call
+type Call struct {
+	Model        string
+	PromptTokens int64
+	OutputTokens int64
+}
+
+// Usage collects the GenerateContent calls made with a context returned by
+// WithUsage, e.g. for the audit log and token accounting of a request
+type Usage struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context whose GenerateContent calls are added to the
+// returned Usage
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	u := new(Usage)
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+// Calls returns the calls made so far
+func (u *Usage) Calls() []Call {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]Call(nil), u.calls...)
+}
+
+// Tokens returns the prompt and output tokens of the calls made so far
+func (u *Usage) Tokens() (prompt, output int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, call := range u.calls {
+		prompt += call.PromptTokens
+		output += call.OutputTokens
+	}
+	return prompt, output
+}
+
+// addUsage records a call made with ctx
+func addUsage(ctx context.Context, call Call) {
+	u, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.calls = append(u.calls, call)
+}
@@ -0,0 +1,213 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"curly-succotash/backend/internal/model"
+)
+
+// SchemaVersion is the bundle layout written by this version. Bump it when
+// the layout changes incompatibly and keep reading older versions.
+const SchemaVersion = 1
+
+// BundleFile is the name of the bundle inside a zip archive
+const BundleFile = "game.json"
+
+// MaxSize bounds the size of a bundle, and of BundleFile once decompressed
+const MaxSize = 16 << 20
+
+var (
+	// ErrUnsupportedVersion indicates a bundle written by a newer or unknown schema
+	ErrUnsupportedVersion = errors.New("unsupported schema version")
+	// ErrInvalidBundle indicates a bundle that cannot be imported
+	ErrInvalidBundle = errors.New("invalid bundle")
+)
+
+// Bundle is a portable, ID-independent copy of a game
+type Bundle struct {
+	SchemaVersion int              `json:"schema_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Game          Game             `json:"game"`
+	Cards         []Card           `json:"cards"`
+	Meta          map[string]int64 `json:"meta"`
+	Provenance    Provenance       `json:"provenance"`
+}
+
+// Game holds the game fields carried by a bundle
+type Game struct {
+	Theme       string    `json:"theme"`
+	CardCount   int       `json:"card_count"`
+	Style       string    `json:"style"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Card holds the card fields carried by a bundle
+type Card struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Effect      string `json:"effect"`
+}
+
+// Provenance records where and by whom the game was created
+type Provenance struct {
+	SourceGameID uint32 `json:"source_game_id"`
+	SourceDB     string `json:"source_db"`
+	CreatedBy    string `json:"created_by"`
+	CreatedOn    uint32 `json:"created_on"`
+	ModifiedBy   string `json:"modified_by"`
+	ModifiedOn   uint32 `json:"modified_on"`
+}
+
+// MetaPrefix returns the prefix of the meta keys belonging to a game.
+// Bundles store meta keys without it so they can be re-keyed on import.
+func MetaPrefix(gameID uint32) string {
+	return fmt.Sprintf("game_%d_", gameID)
+}
+
+// New builds a bundle from stored records
+func New(game model.Game, cards []model.Card, metas []model.Meta, sourceDB string) Bundle {
+	b := Bundle{
+		SchemaVersion: SchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Game: Game{
+			Theme:       game.Theme,
+			CardCount:   game.CardCount,
+			Style:       game.Style,
+			Description: game.Description,
+			CreatedAt:   game.CreatedAt,
+		},
+		Cards: make([]Card, 0, len(cards)),
+		Meta:  make(map[string]int64, len(metas)),
+		Provenance: Provenance{
+			SourceGameID: game.ID,
+			SourceDB:     sourceDB,
+			CreatedBy:    game.CreatedBy,
+			CreatedOn:    game.CreatedOn,
+			ModifiedBy:   game.ModifiedBy,
+			ModifiedOn:   game.ModifiedOn,
+		},
+	}
+	for _, card := range cards {
+		b.Cards = append(b.Cards, Card{
+			Type:        card.Type,
+			Name:        card.Name,
+			Description: card.Description,
+			Effect:      card.Effect,
+		})
+	}
+	prefix := MetaPrefix(game.ID)
+	for _, meta := range metas {
+		b.Meta[strings.TrimPrefix(meta.Key, prefix)] = meta.Value
+	}
+	return b
+}
+
+// Validate checks the schema version and the fields required to recreate the game
+func (b *Bundle) Validate() error {
+	if b.SchemaVersion < 1 || b.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("%w: %d (supported: 1-%d)", ErrUnsupportedVersion, b.SchemaVersion, SchemaVersion)
+	}
+	if strings.TrimSpace(b.Game.Theme) == "" {
+		return fmt.Errorf("%w: game theme is required", ErrInvalidBundle)
+	}
+	if strings.TrimSpace(b.Game.Style) == "" {
+		return fmt.Errorf("%w: game style is required", ErrInvalidBundle)
+	}
+	for i, card := range b.Cards {
+		if strings.TrimSpace(card.Name) == "" {
+			return fmt.Errorf("%w: card %d has no name", ErrInvalidBundle, i+1)
+		}
+		if strings.TrimSpace(card.Type) == "" {
+			return fmt.Errorf("%w: card %d has no type", ErrInvalidBundle, i+1)
+		}
+	}
+	for key := range b.Meta {
+		if key == "" || strings.HasPrefix(key, "game_") {
+			return fmt.Errorf("%w: meta key %q must not carry a game prefix", ErrInvalidBundle, key)
+		}
+	}
+	return nil
+}
+
+// WriteZip writes the bundle as a zip archive containing BundleFile
+func (b *Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(BundleFile)
+	if err != nil {
+		return fmt.Errorf("failed to create archive entry: %s", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("failed to encode bundle: %s", err)
+	}
+	return zw.Close()
+}
+
+// Parse reads a bundle from either its JSON form or a zip archive
+// containing BundleFile, and validates it
+func Parse(data []byte) (*Bundle, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+		}
+		var entry *zip.File
+		for _, f := range zr.File {
+			if f.Name == BundleFile {
+				entry = f
+				break
+			}
+		}
+		if entry == nil {
+			return nil, fmt.Errorf("%w: archive has no %s", ErrInvalidBundle, BundleFile)
+		}
+		if entry.UncompressedSize64 > MaxSize {
+			return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidBundle, BundleFile, MaxSize)
+		}
+		f, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+		}
+		defer f.Close()
+		// The declared size may lie, so bound the read as well
+		if data, err = io.ReadAll(io.LimitReader(f, MaxSize+1)); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+		}
+		if len(data) > MaxSize {
+			return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidBundle, BundleFile, MaxSize)
+		}
+	}
+
+	// Check the version first so bundles from newer schemas are reported as
+	// such rather than as unknown fields
+	var probe struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	if probe.SchemaVersion < 1 || probe.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%w: %d (supported: 1-%d)", ErrUnsupportedVersion, probe.SchemaVersion, SchemaVersion)
+	}
+
+	var b Bundle
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"curly-succotash/backend/internal/model"
+)
+
+func testBundle() Bundle {
+	game := model.Game{Theme: "Space pirates", Style: "classic", CardCount: 1}
+	game.ID = 7
+	cards := []model.Card{{Type: "action", Name: "Board", Description: "Board a ship", Effect: "Steal a card"}}
+	metas := []model.Meta{{Key: "game_7_turns", Value: 3}}
+	return New(game, cards, metas, "sqlite")
+}
+
+func zipped(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	b := testBundle()
+	var buf bytes.Buffer
+	if err := b.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got.Game.Theme != b.Game.Theme || len(got.Cards) != 1 || got.Cards[0].Name != "Board" {
+		t.Errorf("Parse = %+v, want %+v", got, b)
+	}
+	if got.Meta["turns"] != 3 {
+		t.Errorf("meta turns = %d, want 3 without the game prefix", got.Meta["turns"])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"not json", []byte("hello"), ErrInvalidBundle},
+		{"newer version", []byte(`{"schema_version": 2}`), ErrUnsupportedVersion},
+		{"missing version", []byte(`{"game": {}}`), ErrUnsupportedVersion},
+		{"unknown field", []byte(`{"schema_version": 1, "game": {"theme": "a", "style": "b"}, "extra": 1}`), ErrInvalidBundle},
+		{"no theme", []byte(`{"schema_version": 1, "game": {"style": "b"}}`), ErrInvalidBundle},
+		{"card without name", []byte(`{"schema_version": 1, "game": {"theme": "a", "style": "b"}, "cards": [{"type": "t"}]}`), ErrInvalidBundle},
+		{"prefixed meta", []byte(`{"schema_version": 1, "game": {"theme": "a", "style": "b"}, "meta": {"game_1_x": 1}}`), ErrInvalidBundle},
+		{"zip without bundle", zipped(t, "other.json", []byte(`{}`)), ErrInvalidBundle},
+		{"truncated zip", []byte("PK\x03\x04garbage"), ErrInvalidBundle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseZipTooLarge(t *testing.T) {
+	// Compresses to a few kilobytes but decompresses past MaxSize
+	data := zipped(t, BundleFile, []byte(`{"schema_version": 1, "pad": "`+strings.Repeat(" ", MaxSize)+`"}`))
+	if len(data) > MaxSize/100 {
+		t.Fatalf("test archive is %d bytes, expected it to compress well", len(data))
+	}
+	_, err := Parse(data)
+	if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Parse() error = %v, want the size limit", err)
+	}
+}
@@ -0,0 +1,165 @@
+package cardimage
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/font"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Format is an image encoding supported for card exports
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+// Width limits, in pixels
+const (
+	DefaultWidth = 750
+	MinWidth     = 150
+	MaxWidth     = 2000
+)
+
+// ErrUnsupportedFormat indicates the requested format cannot be rendered
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ParseFormat validates a format name, defaulting to PNG
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatPNG:
+		return FormatPNG, nil
+	case FormatSVG:
+		return FormatSVG, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Render draws a single card with the colors and icons of the theme
+func Render(w io.Writer, card model.Card, t *theme.Theme, format Format, width int) error {
+	if width < MinWidth || width > MaxWidth {
+		return fmt.Errorf("width must be between %d and %d", MinWidth, MaxWidth)
+	}
+	faces := newFaceSet()
+	defer faces.close()
+	d := layout(card, t, width, faces.measure)
+
+	switch format {
+	case FormatPNG:
+		return renderPNG(w, d, faces)
+	case FormatSVG:
+		return renderSVG(w, d)
+	}
+	return ErrUnsupportedFormat
+}
+
+func renderPNG(w io.Writer, d drawing, faces *faceSet) error {
+	img, err := draw2D(d, faces)
+	if err != nil {
+		return err
+	}
+	return EncodePNG(w, img)
+}
+
+func draw2D(d drawing, faces *faceSet) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, d.Width, d.Height))
+	for _, r := range d.Rects {
+		if r.Stroke != "" && r.StrokeWidth > 0 {
+			fillRoundedRect(img, r.X, r.Y, r.W, r.H, r.Radius, parseColor(r.Stroke))
+			fillRoundedRect(img, r.X+r.StrokeWidth, r.Y+r.StrokeWidth, r.W-2*r.StrokeWidth, r.H-2*r.StrokeWidth, math.Max(r.Radius-r.StrokeWidth, 0), parseColor(r.Fill))
+			continue
+		}
+		fillRoundedRect(img, r.X, r.Y, r.W, r.H, r.Radius, parseColor(r.Fill))
+	}
+
+	for _, l := range d.Lines {
+		dr := &xfont.Drawer{
+			Dst: img,
+			Src: image.NewUniform(parseColor(l.Color)),
+			Dot: fixed.P(int(l.X), int(l.Y)),
+		}
+		for _, run := range font.Split(l.Text) {
+			face, err := faces.face(run.Script, l.Size, l.Bold)
+			if err != nil {
+				return nil, err
+			}
+			dr.Face = face
+			dr.DrawString(run.Text)
+		}
+	}
+	return img, nil
+}
+
+// fillRoundedRect paints a rectangle whose corners are rounded by radius
+func fillRoundedRect(img *image.RGBA, x, y, w, h, radius float64, c color.Color) {
+	if radius <= 0 {
+		draw.Draw(img, image.Rect(int(x), int(y), int(x+w), int(y+h)), image.NewUniform(c), image.Point{}, draw.Over)
+		return
+	}
+	for py := int(y); py < int(y+h); py++ {
+		for px := int(x); px < int(x+w); px++ {
+			fx, fy := float64(px)+0.5, float64(py)+0.5
+			cx := math.Max(x+radius, math.Min(fx, x+w-radius))
+			cy := math.Max(y+radius, math.Min(fy, y+h-radius))
+			if (fx-cx)*(fx-cx)+(fy-cy)*(fy-cy) <= radius*radius {
+				img.Set(px, py, c)
+			}
+		}
+	}
+}
+
+func parseColor(hex string) color.RGBA {
+	r, g, b := theme.RGB(hex)
+	return color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xff}
+}
+
+func renderSVG(w io.Writer, d drawing) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", d.Width, d.Height, d.Width, d.Height)
+	fmt.Fprintf(&b, "<style>text { font-family: %s; }</style>\n", xmlEscape(global.Fonts.FamilyStack()))
+	for _, r := range d.Rects {
+		inset := r.StrokeWidth / 2
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.2f" fill="%s"`, r.X+inset, r.Y+inset, r.W-2*inset, r.H-2*inset, r.Radius, xmlEscape(r.Fill))
+		if r.Stroke != "" && r.StrokeWidth > 0 {
+			fmt.Fprintf(&b, ` stroke="%s" stroke-width="%.2f"`, xmlEscape(r.Stroke), r.StrokeWidth)
+		}
+		b.WriteString("/>\n")
+	}
+	for _, l := range d.Lines {
+		weight := "normal"
+		if l.Bold {
+			weight = "bold"
+		}
+		fmt.Fprintf(&b, `<text x="%.2f" y="%.2f" font-size="%.2f" font-weight="%s" fill="%s">%s</text>`+"\n", l.X, l.Y, l.Size, weight, xmlEscape(l.Color), xmlEscape(l.Text))
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var xmlReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func xmlEscape(s string) string {
+	return xmlReplacer.Replace(s)
+}
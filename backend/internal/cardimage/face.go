@@ -0,0 +1,107 @@
+package cardimage
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/font"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	parsedMu sync.Mutex
+	parsed   = map[string]*opentype.Font{}
+)
+
+// faceSet picks a parsed font per script, falling back to the Go fonts
+// when the registry has nothing suitable
+type faceSet struct {
+	faces map[string]xfont.Face
+}
+
+func newFaceSet() *faceSet {
+	return &faceSet{faces: map[string]xfont.Face{}}
+}
+
+// face returns the face drawing the given script at a pixel size
+func (fs *faceSet) face(s font.Script, size float64, bold bool) (xfont.Face, error) {
+	path := ""
+	if f, ok := global.Fonts.Face(s); ok {
+		path = f.Path
+	}
+	key := fmt.Sprintf("%s|%v|%.2f", path, bold, size)
+	if face, ok := fs.faces[key]; ok {
+		return face, nil
+	}
+
+	otf, err := loadFont(path, bold)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(otf, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: xfont.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %s", err)
+	}
+	fs.faces[key] = face
+	return face, nil
+}
+
+// measure returns the advance width of text, switching faces per script run
+func (fs *faceSet) measure(text string, size float64, bold bool) float64 {
+	var width fixed.Int26_6
+	for _, run := range font.Split(text) {
+		face, err := fs.face(run.Script, size, bold)
+		if err != nil {
+			continue
+		}
+		width += xfont.MeasureString(face, run.Text)
+	}
+	return float64(width) / 64
+}
+
+func (fs *faceSet) close() {
+	for _, face := range fs.faces {
+		face.Close()
+	}
+}
+
+// loadFont parses a TrueType file once and keeps it for later renders. An
+// empty path selects the bundled Go fonts.
+func loadFont(path string, bold bool) (*opentype.Font, error) {
+	key := path
+	if path == "" {
+		key = fmt.Sprintf("go|%v", bold)
+	}
+	parsedMu.Lock()
+	defer parsedMu.Unlock()
+	if f, ok := parsed[key]; ok {
+		return f, nil
+	}
+
+	var data []byte
+	switch {
+	case path != "":
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font %s: %s", path, err)
+		}
+		data = b
+	case bold:
+		data = gobold.TTF
+	default:
+		data = goregular.TTF
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %s", path, err)
+	}
+	parsed[key] = f
+	return f, nil
+}
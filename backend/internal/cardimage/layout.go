@@ -0,0 +1,162 @@
+package cardimage
+
+import (
+	"strings"
+	"unicode"
+
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/font"
+)
+
+// Card proportions follow a standard poker card (63.5mm x 88.9mm)
+const aspectRatio = 88.9 / 63.5
+
+// Height returns the image height for a card of the given width
+func Height(width int) int {
+	return int(float64(width) * aspectRatio)
+}
+
+// rect is a filled and optionally stroked rectangle
+type rect struct {
+	X, Y, W, H   float64
+	Radius       float64
+	Fill, Stroke string
+	StrokeWidth  float64
+}
+
+// textLine is a single line of text drawn from its baseline
+type textLine struct {
+	X, Y  float64
+	Size  float64
+	Bold  bool
+	Color string
+	Text  string
+}
+
+// drawing is a renderer-independent description of a card
+type drawing struct {
+	Width, Height int
+	Rects         []rect
+	Lines         []textLine
+}
+
+// measurer returns the advance width of text at the given font size
+type measurer func(text string, size float64, bold bool) float64
+
+// layout positions the card elements, wrapping text with measure so both
+// the PNG and SVG renderers produce the same line breaks
+func layout(card model.Card, t *theme.Theme, width int, measure measurer) drawing {
+	w := float64(width)
+	h := float64(Height(width))
+	pad := w * 0.06
+	style := t.Style(card.Type)
+
+	d := drawing{Width: width, Height: int(h)}
+	d.Rects = append(d.Rects,
+		rect{X: 0, Y: 0, W: w, H: h, Radius: w * 0.04, Fill: t.Background, Stroke: t.Border, StrokeWidth: w * 0.008},
+		rect{X: w * 0.008, Y: w * 0.008, W: w - w*0.016, H: w * 0.05, Fill: style.Color},
+	)
+
+	y := w*0.05 + pad
+	bottom := h - pad
+	add := func(text string, size float64, bold bool, color string, gapAfter float64) {
+		for _, line := range wrap(text, size, bold, w-2*pad, measure) {
+			if y+size > bottom {
+				return
+			}
+			y += size
+			d.Lines = append(d.Lines, textLine{X: pad, Y: y, Size: size, Bold: bold, Color: color, Text: line})
+			y += size * 0.3
+		}
+		y += gapAfter
+	}
+
+	titleSize := w * 0.075
+	bodySize := w * 0.045
+	add(card.Name, titleSize, true, t.Text, bodySize*0.5)
+	typeLabel := strings.TrimSpace(style.Icon + " " + title(card.Type))
+	add(typeLabel, bodySize*1.1, false, style.Color, bodySize)
+	add(card.Description, bodySize, false, t.Text, bodySize)
+	if card.Effect != "" {
+		add("Effect: "+card.Effect, bodySize, true, t.Text, 0)
+	}
+	return d
+}
+
+// layoutBack positions the shared card back: the theme border around the
+// game title, centered vertically
+func layoutBack(gameTitle string, t *theme.Theme, width int, measure measurer) drawing {
+	w := float64(width)
+	h := float64(Height(width))
+	pad := w * 0.12
+
+	d := drawing{Width: width, Height: int(h)}
+	d.Rects = append(d.Rects,
+		rect{X: 0, Y: 0, W: w, H: h, Radius: w * 0.04, Fill: t.Border, Stroke: t.Border, StrokeWidth: w * 0.008},
+		rect{X: w * 0.06, Y: w * 0.06, W: w - w*0.12, H: h - w*0.12, Radius: w * 0.03, Fill: t.Background, Stroke: t.Border, StrokeWidth: w * 0.008},
+	)
+
+	size := w * 0.09
+	lines := wrap(gameTitle, size, true, w-2*pad, measure)
+	y := (h-float64(len(lines))*size*1.3)/2 + size
+	for _, line := range lines {
+		x := (w - measure(line, size, true)) / 2
+		d.Lines = append(d.Lines, textLine{X: x, Y: y, Size: size, Bold: true, Color: t.Text, Text: line})
+		y += size * 1.3
+	}
+	return d
+}
+
+// wrap breaks text into lines no wider than maxWidth. Latin text breaks at
+// spaces; CJK characters may break anywhere.
+func wrap(text string, size float64, bold bool, maxWidth float64, measure measurer) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line string
+		for _, token := range tokens(paragraph) {
+			candidate := line + token
+			if line != "" && measure(strings.TrimRight(candidate, " "), size, bold) > maxWidth {
+				lines = append(lines, strings.TrimRight(line, " "))
+				candidate = strings.TrimLeft(token, " ")
+			}
+			line = candidate
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// tokens splits text into unbreakable pieces: words with their trailing
+// space, or single CJK characters
+func tokens(text string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			out = append(out, b.String())
+			b.Reset()
+		}
+	}
+	for _, c := range text {
+		switch {
+		case font.DetectScript(c) == font.ScriptHan || font.DetectScript(c) == font.ScriptJapanese:
+			flush()
+			out = append(out, string(c))
+		case unicode.IsSpace(c):
+			b.WriteRune(' ')
+			flush()
+		default:
+			b.WriteRune(c)
+		}
+	}
+	flush()
+	return out
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,56 @@
+package cardimage
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/theme"
+)
+
+// RenderSheets lays cards out on grid sheets of cols x rows cards, as used by
+// virtual tabletops to import a whole deck from a single image. Cards are
+// placed left to right, top to bottom; a new sheet starts when one is full.
+func RenderSheets(cards []model.Card, t *theme.Theme, cols, rows, cardWidth int) ([]*image.RGBA, error) {
+	perSheet := cols * rows
+	cardHeight := Height(cardWidth)
+	faces := newFaceSet()
+	defer faces.close()
+
+	var sheets []*image.RGBA
+	for i, card := range cards {
+		slot := i % perSheet
+		if slot == 0 {
+			sheets = append(sheets, image.NewRGBA(image.Rect(0, 0, cols*cardWidth, rows*cardHeight)))
+		}
+		img, err := draw2D(layout(card, t, cardWidth, faces.measure), faces)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render card %d: %s", card.ID, err)
+		}
+		x, y := (slot%cols)*cardWidth, (slot/cols)*cardHeight
+		draw.Draw(sheets[len(sheets)-1], image.Rect(x, y, x+cardWidth, y+cardHeight), img, image.Point{}, draw.Src)
+	}
+	return sheets, nil
+}
+
+// RenderBack draws the card back shared by every card of a game as a PNG
+func RenderBack(w io.Writer, gameTitle string, t *theme.Theme, width int) error {
+	faces := newFaceSet()
+	defer faces.close()
+	img, err := draw2D(layoutBack(gameTitle, t, width, faces.measure), faces)
+	if err != nil {
+		return err
+	}
+	return EncodePNG(w, img)
+}
+
+// EncodePNG writes an image rendered by this package as PNG
+func EncodePNG(w io.Writer, img image.Image) error {
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package cardtable
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"curly-succotash/backend/internal/model"
+)
+
+// Column is a card field that can appear in a tabular export
+type Column string
+
+const (
+	ColumnID          Column = "id"
+	ColumnType        Column = "type"
+	ColumnName        Column = "name"
+	ColumnDescription Column = "description"
+	ColumnEffect      Column = "effect"
+)
+
+// DefaultColumns are exported when the request does not choose any
+var DefaultColumns = []Column{ColumnID, ColumnType, ColumnName, ColumnDescription, ColumnEffect}
+
+// editable lists the columns a CSV import may change
+var editable = map[Column]bool{
+	ColumnType:        true,
+	ColumnName:        true,
+	ColumnDescription: true,
+	ColumnEffect:      true,
+}
+
+// ParseColumns reads a comma separated column list, returning DefaultColumns when empty
+func ParseColumns(s string) ([]Column, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultColumns, nil
+	}
+	var cols []Column
+	seen := map[Column]bool{}
+	for _, name := range strings.Split(s, ",") {
+		col := Column(strings.ToLower(strings.TrimSpace(name)))
+		if !valid(col) {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		seen[col] = true
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func valid(col Column) bool {
+	for _, c := range DefaultColumns {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the text of a column for a card
+func Value(card model.Card, col Column) string {
+	switch col {
+	case ColumnID:
+		return strconv.FormatUint(uint64(card.ID), 10)
+	case ColumnType:
+		return card.Type
+	case ColumnName:
+		return card.Name
+	case ColumnDescription:
+		return card.Description
+	case ColumnEffect:
+		return card.Effect
+	}
+	return ""
+}
+
+// WriteCSV writes the cards as CSV with a header row. Cells are escaped
+// with escapeCell so spreadsheets do not evaluate card text as formulas;
+// ParseCSV undoes the escaping, so exports can be imported unchanged.
+func WriteCSV(w io.Writer, cards []model.Card, cols []Column) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = string(col)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, card := range cards {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = escapeCell(Value(card, col))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formulaPrefixes are the leading characters that make spreadsheets
+// evaluate a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeCell keeps spreadsheets from evaluating a cell as a formula by
+// prefixing values starting with =, +, -, @, a tab or a carriage return
+// with a single quote. Values already starting with a quote get another
+// one, so unescapeCell restores every value exactly.
+func escapeCell(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes+"'", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescapeCell removes the quote added by escapeCell
+func unescapeCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes+"'", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+var markdownReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// WriteMarkdown writes the game as a Markdown document with a card table
+func WriteMarkdown(w io.Writer, game model.Game, cards []model.Card, cols []Column) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", game.Theme)
+	fmt.Fprintf(&b, "- **Style:** %s\n- **Cards:** %d\n\n", game.Style, len(cards))
+	if game.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", game.Description)
+	}
+
+	b.WriteString("|")
+	for _, col := range cols {
+		fmt.Fprintf(&b, " %s |", strings.ToUpper(string(col[:1]))+string(col[1:]))
+	}
+	b.WriteString("\n|")
+	for range cols {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, card := range cards {
+		b.WriteString("|")
+		for _, col := range cols {
+			fmt.Fprintf(&b, " %s |", markdownReplacer.Replace(Value(card, col)))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
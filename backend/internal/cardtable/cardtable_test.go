@@ -0,0 +1,63 @@
+package cardtable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"curly-succotash/backend/internal/model"
+)
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	names := []string{"Knight", "=HYPERLINK(\"http://x\")", "+1 Attack", "-2 Gold", "@SUM(A1)", "\tTabbed", "'Quoted", "'=Both", "Mid=dle"}
+	cards := make([]model.Card, len(names))
+	for i, name := range names {
+		cards[i] = model.Card{Type: "role", Name: name}
+		cards[i].ID = uint32(i + 1)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, cards, []Column{ColumnID, ColumnName, ColumnEffect}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[1:] {
+		cells := strings.SplitN(line, ",", 2)
+		if cell := strings.TrimPrefix(cells[1], `"`); cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			t.Errorf("row %q starts a cell with a formula character", line)
+		}
+	}
+
+	edits, rowErrs, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	if len(rowErrs) != 0 {
+		t.Fatalf("row errors = %v, want none", rowErrs)
+	}
+	if len(edits) != len(cards) {
+		t.Fatalf("got %d edits, want %d", len(edits), len(cards))
+	}
+	for i, edit := range edits {
+		if edit.CardID != cards[i].ID || edit.Fields[ColumnName] != cards[i].Name || edit.Fields[ColumnEffect] != "" {
+			t.Errorf("edit %d = %+v, want card %d named %q", i, edit, cards[i].ID, cards[i].Name)
+		}
+	}
+}
+
+func TestUnescapeCell(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"'=1+1", "=1+1"},
+		{"''Quoted", "'Quoted"},
+		{"'Quoted", "'Quoted"},
+		{"'", "'"},
+		{"=1+1", "=1+1"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := unescapeCell(tt.in); got != tt.want {
+			t.Errorf("unescapeCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
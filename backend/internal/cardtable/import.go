@@ -0,0 +1,114 @@
+package cardtable
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidHeader indicates a CSV whose header row cannot be imported
+var ErrInvalidHeader = errors.New("invalid CSV header")
+
+// RowError reports why a CSV row cannot be applied. Row numbers count the
+// header as row 1, matching what spreadsheets show.
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the error as shown to API clients
+func (e RowError) String() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Message)
+}
+
+// Edit is a change to an existing card read from one CSV row
+type Edit struct {
+	Row    int
+	CardID uint32
+	Fields map[Column]string
+}
+
+// ParseCSV reads card edits from a CSV with a header row. The header must
+// contain the id column; other known columns are applied, unknown columns
+// are rejected so typos are not silently ignored. Cells escaped by WriteCSV
+// are read back as they were exported.
+func ParseCSV(r io.Reader) ([]Edit, []RowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidHeader, err)
+	}
+
+	idIndex := -1
+	cols := make([]Column, len(header))
+	seenCols := map[Column]bool{}
+	for i, name := range header {
+		// Spreadsheets often prefix UTF-8 exports with a byte order mark
+		col := Column(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))))
+		switch {
+		case seenCols[col]:
+			return nil, nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidHeader, name)
+		case col == ColumnID:
+			idIndex = i
+		case !editable[col]:
+			return nil, nil, fmt.Errorf("%w: unknown column %q", ErrInvalidHeader, name)
+		}
+		seenCols[col] = true
+		cols[i] = col
+	}
+	if idIndex < 0 {
+		return nil, nil, fmt.Errorf("%w: missing id column", ErrInvalidHeader)
+	}
+
+	var edits []Edit
+	var rowErrs []RowError
+	seen := map[uint32]int{}
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		if len(record) != len(header) {
+			rowErrs = append(rowErrs, RowError{Row: row, Message: fmt.Sprintf("expected %d fields, found %d", len(header), len(record))})
+			continue
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSpace(record[idIndex]), 10, 32)
+		if err != nil || id == 0 {
+			rowErrs = append(rowErrs, RowError{Row: row, Column: string(ColumnID), Message: fmt.Sprintf("invalid card id %q", record[idIndex])})
+			continue
+		}
+		if first, ok := seen[uint32(id)]; ok {
+			rowErrs = append(rowErrs, RowError{Row: row, Column: string(ColumnID), Message: fmt.Sprintf("card %d already edited in row %d", id, first)})
+			continue
+		}
+		seen[uint32(id)] = row
+
+		edit := Edit{Row: row, CardID: uint32(id), Fields: map[Column]string{}}
+		for i, col := range cols {
+			if i == idIndex {
+				continue
+			}
+			value := unescapeCell(strings.TrimSpace(record[i]))
+			if (col == ColumnName || col == ColumnType) && value == "" {
+				rowErrs = append(rowErrs, RowError{Row: row, Column: string(col), Message: "must not be empty"})
+				continue
+			}
+			edit.Fields[col] = value
+		}
+		edits = append(edits, edit)
+	}
+	return edits, rowErrs, nil
+}
@@ -0,0 +1,75 @@
+package cardtable
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	csv := "\ufeffID, Name ,effect\n" +
+		"1,Board,Steal a card\n" +
+		" 2 , Sail , \n"
+	edits, rowErrs, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	if len(rowErrs) != 0 {
+		t.Errorf("row errors = %v, want none", rowErrs)
+	}
+	want := []Edit{
+		{Row: 2, CardID: 1, Fields: map[Column]string{ColumnName: "Board", ColumnEffect: "Steal a card"}},
+		{Row: 3, CardID: 2, Fields: map[Column]string{ColumnName: "Sail", ColumnEffect: ""}},
+	}
+	if !reflect.DeepEqual(edits, want) {
+		t.Errorf("edits = %+v, want %+v", edits, want)
+	}
+}
+
+func TestParseCSVHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{"empty", ""},
+		{"missing id", "name,effect\nBoard,x\n"},
+		{"unknown column", "id,nmae\n1,Board\n"},
+		{"duplicate column", "id,name,Name\n1,a,b\n"},
+		{"duplicate id", "id,ID\n1,1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseCSV(strings.NewReader(tt.csv)); !errors.Is(err, ErrInvalidHeader) {
+				t.Errorf("ParseCSV() error = %v, want %v", err, ErrInvalidHeader)
+			}
+		})
+	}
+}
+
+func TestParseCSVRowErrors(t *testing.T) {
+	csv := "id,name,type\n" +
+		"1,Board,action\n" +
+		"x,Sail,action\n" +
+		"0,Sail,action\n" +
+		"1,Again,action\n" +
+		"2,Only two\n" +
+		"3,,action\n"
+	edits, rowErrs, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	want := []RowError{
+		{Row: 3, Column: "id", Message: `invalid card id "x"`},
+		{Row: 4, Column: "id", Message: `invalid card id "0"`},
+		{Row: 5, Column: "id", Message: "card 1 already edited in row 2"},
+		{Row: 6, Message: "expected 3 fields, found 2"},
+		{Row: 7, Column: "name", Message: "must not be empty"},
+	}
+	if !reflect.DeepEqual(rowErrs, want) {
+		t.Errorf("row errors = %+v, want %+v", rowErrs, want)
+	}
+	if len(edits) == 0 || edits[0].CardID != 1 || edits[0].Fields[ColumnName] != "Board" {
+		t.Errorf("edits = %+v, want card 1 renamed to Board first", edits)
+	}
+}
@@ -45,6 +45,18 @@ func (eng *SQLiteStorageEngine) Open() error {
 	return nil
 }
 
+// Ping verifies the database connection is alive
+func (eng *SQLiteStorageEngine) Ping(ctx context.Context) error {
+	if eng.DB == nil {
+		return errors.New("database not initialized")
+	}
+	sqlDB, err := eng.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying SQL DB: %s", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // Close closes the database connection
 func (eng *SQLiteStorageEngine) Close() error {
 	if eng.DB == nil {
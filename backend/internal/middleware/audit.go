@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextGameID holds the ID of a game created by the request, recorded as
+// the target of its audit log entry
+const ContextGameID = "game_id"
+
+// Audit parameter limits: larger JSON bodies are not recorded, and longer
+// string values are truncated
+const (
+	maxAuditBody  = 64 << 10
+	maxAuditValue = 256
+)
+
+// auditSecretKeys are suffixes of parameter names left out of audit logs.
+// share is the query parameter carrying share link tokens.
+var auditSecretKeys = []string{"password", "secret", "token", "share"}
+
+// Audit records the request in the audit log once it is handled: the actor,
+// client IP and the address of the connection, which differs when a trusted
+// proxy forwarded the request, target, query and JSON body parameters
+// (without passwords, secrets, tokens and share links), response status and
+// the AI tokens used, whose calls are also recorded for token accounting. It
+// goes before the scope and quota middleware so denied requests are
+// recorded. Failing to record is logged and does not fail the request.
+func Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := auditParams(c)
+		ctx, usage := ai.WithUsage(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		entry := model.AuditLog{
+			Action:   action,
+			Actor:    c.GetString(ContextUsername),
+			IP:       c.ClientIP(),
+			RemoteIP: c.RemoteIP(),
+			Route:    c.FullPath(),
+			Status:   c.Writer.Status(),
+			Outcome:  service.AuditOutcome(c.Writer.Status()),
+		}
+		entry.PromptTokens, entry.OutputTokens = usage.Tokens()
+		entry.TargetType, entry.TargetID = auditTarget(c)
+		if _, created := c.Get(ContextGameID); created && c.Param("id") != "" {
+			// Forks target the new game; keep the game they were forked from
+			params["source_id"] = c.Param("id")
+		}
+		if username, ok := params["username"].(string); ok {
+			if entry.Actor == "" {
+				entry.Actor = username
+			}
+			if entry.TargetType == "" {
+				entry.TargetType, entry.TargetID = model.AuditTargetUser, username
+			}
+		}
+		if len(params) > 0 {
+			entry.Params, _ = json.Marshal(params)
+		}
+
+		ctx = context.WithoutCancel(c.Request.Context())
+		if err := service.RecordAudit(ctx, &entry, usage.Calls()); err != nil {
+			global.Logger.Errorf(ctx, "%s", err)
+		}
+	}
+}
+
+// auditTarget returns the game created by the request, or the game or app
+// key of the route's :id parameter
+func auditTarget(c *gin.Context) (string, string) {
+	if id, ok := c.Get(ContextGameID); ok {
+		return model.AuditTargetGame, strconv.FormatUint(uint64(id.(uint32)), 10)
+	}
+	id := c.Param("id")
+	switch {
+	case id == "":
+		return "", ""
+	case gameRoute(c.FullPath()):
+		return model.AuditTargetGame, id
+	case strings.Contains(c.FullPath(), "/appkeys/"):
+		return model.AuditTargetAppKey, id
+	}
+	return "", ""
+}
+
+// auditParams returns the query parameters and the fields of a JSON object
+// body. The body is restored for the handler.
+func auditParams(c *gin.Context) map[string]any {
+	params := map[string]any{}
+	for key, values := range c.Request.URL.Query() {
+		params[key] = truncateAuditValue(strings.Join(values, ","))
+	}
+	if c.Request.Body != nil && c.ContentType() == gin.MIMEJSON {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBody+1))
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		var fields map[string]any
+		if err == nil && len(body) <= maxAuditBody && json.Unmarshal(body, &fields) == nil {
+			for key, value := range fields {
+				if s, ok := value.(string); ok {
+					value = truncateAuditValue(s)
+				}
+				params[key] = value
+			}
+		}
+	}
+	for key := range params {
+		if secretParam(key) {
+			delete(params, key)
+		}
+	}
+	return params
+}
+
+// secretParam reports whether a query or body parameter holds a credential
+func secretParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range auditSecretKeys {
+		if strings.HasSuffix(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateAuditValue(s string) string {
+	if len(s) <= maxAuditValue {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxAuditValue], "") + "..."
+}
+
+// readCloser reads from a restored body and closes the original one
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
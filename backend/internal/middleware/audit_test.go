@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		want   map[string]any
+	}{
+		{"query", "/api/v1/games?page=2&page_size=10", "", map[string]any{"page": "2", "page_size": "10"}},
+		{"share link", "/api/v1/games/5?share=eyJhbGciOiJIUzI1NiJ9.e30.sig", "", map[string]any{}},
+		{"query token", "/api/v1/games/5?token=eyJhbGciOiJIUzI1NiJ9.e30.sig&format=tts", "", map[string]any{"format": "tts"}},
+		{"body secrets", "/auth", `{"app_key": "ak_1", "app_secret": "s", "password": "p", "refresh_token": "t", "username": "alice"}`, map[string]any{"app_key": "ak_1", "username": "alice"}},
+		{"suffix is case insensitive", "/auth?Share=x&NewPassword=y", "", map[string]any{}},
+		{"long value", "/api/v1/games?theme=" + strings.Repeat("a", maxAuditValue+1), "", map[string]any{"theme": strings.Repeat("a", maxAuditValue) + "..."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				c.Request.Header.Set("Content-Type", gin.MIMEJSON)
+			}
+			if got := auditParams(c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("auditParams() = %v, want %v", got, tt.want)
+			}
+			if body, _ := io.ReadAll(c.Request.Body); string(body) != tt.body {
+				t.Errorf("body after auditParams = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
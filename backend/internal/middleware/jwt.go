@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Context keys set for authenticated requests
+const (
+	ContextUserID   = "user_id"
+	ContextUsername = "username"
+	ContextAppKey   = "app_key"
+	ContextScopes   = "scopes"
+)
+
+// JWT requires a valid access token, sent as "Authorization: Bearer <token>"
+// or in the token header or query parameter. Tokens issued for an AppKey are
+// rejected once the key is revoked or rotated. The user, or the user owning
+// the AppKey, becomes the actor recorded in CreatedBy/ModifiedBy.
+func JWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			claims *app.Claims
+			ecode  = errcode.Success
+		)
+		token := bearerToken(c)
+		if token == "" {
+			ecode = errcode.UnauthorizedTokenError.WithDetails("missing token")
+		} else {
+			var err error
+			claims, err = app.ParseToken(token, app.TokenTypeAccess)
+			if err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					ecode = errcode.UnauthorizedTokenTimeout
+				} else {
+					ecode = errcode.UnauthorizedTokenError
+				}
+			} else if claims.AppKey != "" {
+				if err := service.CheckAppToken(c.Request.Context(), claims); err != nil {
+					if errors.Is(err, service.ErrAppKeyNotFound) {
+						ecode = errcode.UnauthorizedAuthNotExist
+					} else {
+						global.Logger.Errorf(c.Request.Context(), "failed to check app key: %s", err)
+						ecode = errcode.ServerError
+					}
+				}
+			}
+		}
+
+		if ecode != errcode.Success {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(ecode)
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextUserID, claims.UserID)
+		c.Set(ContextUsername, claims.Username)
+		c.Set(ContextAppKey, claims.AppKey)
+		c.Set(ContextScopes, claims.Scopes)
+		ctx := logger.With(app.WithActor(c.Request.Context(), claims.Username), "user", claims.Username)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return c.GetHeader("token")
+}
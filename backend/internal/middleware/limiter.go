@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/limiter"
+	"curly-succotash/backend/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter takes a token from the bucket of the request and rejects it
+// with errcode.TooManyRequests when the bucket is empty. Requests without a
+// bucket are not limited, and requests are let through when the limiter
+// store fails. The bucket state is reported in X-RateLimit-* headers, plus
+// Retry-After on rejection.
+func RateLimiter(l limiter.LimiterIface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := l.Key(c)
+		if rule, ok := l.Rule(key); ok {
+			ctx := c.Request.Context()
+			result, err := l.Take(ctx, key, rule)
+			if err != nil {
+				global.Logger.Errorf(ctx, "failed to take rate limit token: %s", err)
+				c.Next()
+				return
+			}
+			c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			if !result.Allowed {
+				metrics.LimiterRejections.WithLabelValues("rate", c.FullPath()).Inc()
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				response := app.NewResponse(c)
+				response.ToErrorResponse(errcode.TooManyRequests)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// LimiterIdentity identifies the caller for per-caller rate limits: the
+// AppKey or user of an authenticated request, otherwise the client IP
+func LimiterIdentity(c *gin.Context) string {
+	if key := c.GetString(ContextAppKey); key != "" {
+		return "app:" + key
+	}
+	if username := c.GetString(ContextUsername); username != "" {
+		return "user:" + username
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"curly-succotash/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger adds the method, the route and, on game routes, the game ID
+// to the records logged with the request context. The trace ID is added by
+// the logger, and the user by JWT.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		args := []any{"method", c.Request.Method, "route", c.FullPath()}
+		if gameRoute(c.FullPath()) {
+			if id, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil {
+				args = append(args, "game_id", id)
+			}
+		}
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), args...))
+		c.Next()
+	}
+}
+
+// gameRoute reports whether the :id parameter of route is a game ID
+func gameRoute(route string) bool {
+	return strings.Contains(route, "/games/:id") || strings.HasSuffix(route, "/generate-pdf/:id")
+}
+
+// AccessLog writes a line per request to gin's default writer in gin's
+// format, with the values of credential query parameters such as share
+// link tokens redacted
+func AccessLog() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor, methodColor, resetColor = param.StatusCodeColor(), param.MethodColor(), param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery replaces the values of the credential parameters in the query
+// of path, keeping the other parameters as sent
+func redactQuery(path string) string {
+	path, query, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && secretParam(name) {
+			pairs[i] = key + "=" + logger.Redacted
+		}
+	}
+	return path + "?" + strings.Join(pairs, "&")
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/games", "/api/v1/games"},
+		{"/api/v1/games?page=2", "/api/v1/games?page=2"},
+		{"/api/v1/games/5?share=eyJ.e30.sig", "/api/v1/games/5?share=[REDACTED]"},
+		{"/api/v1/games/5?theme=dark&share=eyJ.e30.sig&x", "/api/v1/games/5?theme=dark&share=[REDACTED]&x"},
+		{"/api/v1/games?token=abc&Refresh_Token=def", "/api/v1/games?token=[REDACTED]&Refresh_Token=[REDACTED]"},
+		{"/api/v1/games?sh%61re=abc", "/api/v1/games?sh%61re=[REDACTED]"},
+		{"/api/v1/games?share", "/api/v1/games?share=[REDACTED]"},
+	}
+	for _, tt := range tests {
+		if got := redactQuery(tt.path); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
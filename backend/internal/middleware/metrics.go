@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"curly-succotash/backend/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the latency and status code of each request under its
+// route pattern. Requests that match no route share the "unmatched" route so
+// scanners cannot inflate the number of series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// GenerationMetrics counts the request as a generation of kind, with the
+// outcome given by the response status
+func GenerationMetrics(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		metrics.Generations.WithLabelValues(kind, metrics.StatusOutcome(c.Writer.Status())).Inc()
+	}
+}
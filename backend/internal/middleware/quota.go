@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GenerationQuota counts the request against the caller's daily generation
+// quota and rejects it with errcode.TooManyRequests once the quota is used up.
+// Requests failing with an error status are not counted.
+func GenerationQuota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !ConsumeGenerationQuota(c) {
+			c.Abort()
+			return
+		}
+		defer RefundGenerationQuota(c)
+		c.Next()
+	}
+}
+
+// ConsumeGenerationQuota counts one generation for handlers that only
+// sometimes generate content. It writes the error response and returns false
+// when the request must stop.
+func ConsumeGenerationQuota(c *gin.Context) bool {
+	ctx := c.Request.Context()
+	response := app.NewResponse(c)
+
+	quota, err := service.ConsumeGenerationQuota(ctx)
+	if quota.Limit > 0 {
+		c.Header("X-Quota-Limit", strconv.FormatInt(quota.Limit, 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(quota.Remaining, 10))
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			metrics.LimiterRejections.WithLabelValues("quota", c.FullPath()).Inc()
+			response.ToErrorResponse(errcode.TooManyRequests.WithDetails(
+				err.Error(),
+				"resets_at: "+quota.ResetsAt.Format(time.RFC3339),
+			))
+			return false
+		}
+		global.Logger.Errorf(ctx, "failed to check generation quota: %s", err)
+		response.ToErrorResponse(errcode.ServerError)
+		return false
+	}
+	return true
+}
+
+// RefundGenerationQuota gives back the generation counted for the request
+// when the response reports an error. It is meant to be deferred right after
+// a successful ConsumeGenerationQuota.
+func RefundGenerationQuota(c *gin.Context) {
+	if c.Writer.Status() < http.StatusBadRequest {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := service.RefundGenerationQuota(ctx); err != nil {
+		global.Logger.Errorf(ctx, "failed to refund generation quota: %s", err)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGenerationQuota(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Meta{}); err != nil {
+		t.Fatal(err)
+	}
+	previousDB, previousLimiter := global.DBEngine, global.LimiterSetting.Load()
+	global.DBEngine = db
+	global.LimiterSetting.Store(&setting.LimiterSettingS{Store: "memory", DailyGenerations: 2})
+	t.Cleanup(func() {
+		global.DBEngine = previousDB
+		global.LimiterSetting.Store(previousLimiter)
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/generate/:status", GenerationQuota(), func(c *gin.Context) {
+		if c.Param("status") == "fail" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// Failed generations are refunded, so only successes use up the quota
+	steps := []struct {
+		path          string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{"/generate/ok", http.StatusOK, "1"},
+		{"/generate/fail", http.StatusInternalServerError, "0"},
+		{"/generate/ok", http.StatusOK, "0"},
+		{"/generate/ok", http.StatusTooManyRequests, "0"},
+		{"/generate/fail", http.StatusTooManyRequests, "0"},
+	}
+	for i, step := range steps {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, step.path, nil))
+		if w.Code != step.wantStatus {
+			t.Errorf("request %d to %s status = %d, want %d", i+1, step.path, w.Code, step.wantStatus)
+		}
+		if got := w.Header().Get("X-Quota-Limit"); got != "2" {
+			t.Errorf("request %d X-Quota-Limit = %q, want 2", i+1, got)
+		}
+		if got := w.Header().Get("X-Quota-Remaining"); got != step.wantRemaining {
+			t.Errorf("request %d X-Quota-Remaining = %q, want %s", i+1, got, step.wantRemaining)
+		}
+	}
+}
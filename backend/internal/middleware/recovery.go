@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"runtime/debug"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a logged errcode.ServerError
+// response instead of a dropped connection
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				global.Logger.Errorf(c.Request.Context(), "panic recovered: %v\n%s", err, debug.Stack())
+				if !c.Writer.Written() {
+					app.NewResponse(c).ToErrorResponse(errcode.ServerError)
+				}
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
+
+// NotFound writes errcode.NotFound for requests matching no route
+func NotFound(c *gin.Context) {
+	app.NewResponse(c).ToErrorResponse(errcode.NotFound)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"slices"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scope allows requests from signed-in users and from AppKeys granted the
+// scope. It must run after JWT.
+func Scope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(ContextAppKey) != "" && !slices.Contains(c.GetStringSlice(ContextScopes), scope) {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(errcode.Forbidden.WithDetails("app key lacks scope " + scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UserOnly rejects requests authenticated with an AppKey token, for
+// endpoints such as AppKey management that need a signed-in user. It must
+// run after JWT.
+func UserOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(ContextAppKey) != "" {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(errcode.Forbidden.WithDetails("requires a user token"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminOnly allows signed-in users listed in App.Admins, for operational
+// endpoints such as /admin/debug. AppKey tokens are always rejected. It must
+// run after JWT.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(ContextAppKey) != "" || !slices.Contains(global.AppSetting.Load().Admins, c.GetString(ContextUsername)) {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(errcode.Forbidden.WithDetails("requires an admin user"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
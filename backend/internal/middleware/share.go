@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ContextShareGameID is set for requests authorized by a share link
+const ContextShareGameID = "share_game_id"
+
+// JWTOrShare accepts a share link token in the share query parameter for
+// the game named by the :id route parameter, and otherwise requires a JWT
+// like JWT. Use it only on read-only routes.
+func JWTOrShare() gin.HandlerFunc {
+	requireJWT := JWT()
+	return func(c *gin.Context) {
+		token := c.Query("share")
+		if token == "" {
+			requireJWT(c)
+			return
+		}
+
+		ecode := errcode.Success
+		claims, err := app.ParseToken(token, app.TokenTypeShare)
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			ecode = errcode.UnauthorizedTokenTimeout
+		case err != nil, strconv.FormatUint(uint64(claims.GameID), 10) != c.Param("id"):
+			ecode = errcode.UnauthorizedTokenError
+		}
+		if ecode != errcode.Success {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(ecode)
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextShareGameID, claims.GameID)
+		c.Request = c.Request.WithContext(app.WithSharedGame(c.Request.Context(), claims.GameID))
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"curly-succotash/backend/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing exposes the request span started by otelgin, which continues the
+// trace of an incoming W3C traceparent header or starts a new one. The trace
+// is stored in the gin context for the logger and error responses, and
+// echoed in the traceparent and X-Trace-ID response headers. Services, the
+// AI client and database calls pick it up from the request context.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if tc, ok := tracer.FromContext(ctx); ok {
+			c.Set(tracer.ContextTraceID, tc.TraceID)
+			c.Set(tracer.ContextSpanID, tc.SpanID)
+			tracer.Inject(ctx, c.Writer.Header())
+			c.Header(tracer.TraceIDHeader, tc.TraceID)
+		}
+		c.Next()
+	}
+}
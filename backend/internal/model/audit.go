@@ -0,0 +1,64 @@
+package model
+
+import "encoding/json"
+
+// Audited actions. Forks with an instruction regenerate the game's content.
+const (
+	AuditGameGenerate  = "game.generate"
+	AuditGameFork      = "game.fork"
+	AuditGameImport    = "game.import"
+	AuditGameUpdate    = "game.update"
+	AuditGameRollback  = "game.rollback"
+	AuditGameShare     = "game.share"
+	AuditGameExport    = "game.export"
+	AuditCardsImport   = "cards.import"
+	AuditAuthToken     = "auth.token"
+	AuditAuthRegister  = "auth.register"
+	AuditAuthLogin     = "auth.login"
+	AuditAuthRefresh   = "auth.refresh"
+	AuditAppKeyCreate  = "appkey.create"
+	AuditAppKeyRotate  = "appkey.rotate"
+	AuditAppKeyRevoke  = "appkey.revoke"
+	AuditAdminLogLevel = "admin.log_level"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeInvalid = "invalid"
+	AuditOutcomeError   = "error"
+)
+
+// Audit target types
+const (
+	AuditTargetGame   = "game"
+	AuditTargetAppKey = "appkey"
+	AuditTargetUser   = "user"
+)
+
+// AuditLog records who made an audited request, what it targeted, with
+// which parameters, how it ended and the AI tokens it used and their cost
+// in USD
+type AuditLog struct {
+	ID           uint32          `gorm:"primaryKey" json:"id"`
+	Action       string          `gorm:"type:varchar(32);not null;index" json:"action"`
+	Actor        string          `gorm:"type:varchar(64);index" json:"actor"`
+	IP           string          `gorm:"type:varchar(64)" json:"ip"`
+	RemoteIP     string          `gorm:"type:varchar(64)" json:"remote_ip"`
+	Route        string          `gorm:"type:varchar(128)" json:"route"`
+	TargetType   string          `gorm:"type:varchar(16);index:idx_audit_logs_target" json:"target_type,omitempty"`
+	TargetID     string          `gorm:"type:varchar(64);index:idx_audit_logs_target" json:"target_id,omitempty"`
+	Params       json.RawMessage `gorm:"type:text" json:"params,omitempty"`
+	Status       int             `gorm:"not null" json:"status"`
+	Outcome      string          `gorm:"type:varchar(16);not null" json:"outcome"`
+	PromptTokens int64           `gorm:"not null;default:0" json:"prompt_tokens"`
+	OutputTokens int64           `gorm:"not null;default:0" json:"output_tokens"`
+	Cost         float64         `gorm:"not null;default:0" json:"cost"`
+	CreatedOn    uint32          `gorm:"not null;index" json:"created_on"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,40 @@
+package model
+
+import "strings"
+
+// Scopes granted to AppKey credentials. Signed-in users hold every scope.
+const (
+	ScopeGamesRead     = "games:read"
+	ScopeGamesWrite    = "games:write"
+	ScopeGamesGenerate = "games:generate"
+	ScopeGamesExport   = "games:export"
+)
+
+// Scopes lists every scope an AppKey can be granted
+var Scopes = []string{ScopeGamesRead, ScopeGamesWrite, ScopeGamesGenerate, ScopeGamesExport}
+
+// Auth is an AppKey/AppSecret pair used for service-to-service access. Only
+// a SHA-256 hash of the secret is stored; the secret is shown once when the
+// key is created or rotated.
+type Auth struct {
+	Model
+	Name       string `gorm:"type:varchar(128);not null" json:"name"`
+	AppKey     string `gorm:"type:varchar(64);not null;uniqueIndex" json:"app_key"`
+	SecretHash string `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes     string `gorm:"type:varchar(255);not null" json:"scopes"` // comma separated
+	RotatedOn  uint32 `gorm:"not null;default:0" json:"rotated_on"`
+	KeyVersion uint32 `gorm:"not null;default:0" json:"key_version"` // incremented on rotation, see app.Claims
+}
+
+// TableName specifies the table name for Auth
+func (Auth) TableName() string {
+	return "auths"
+}
+
+// ScopeList returns the scopes granted to the key
+func (a Auth) ScopeList() []string {
+	if a.Scopes == "" {
+		return nil
+	}
+	return strings.Split(a.Scopes, ",")
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"curly-succotash/backend/pkg/tracer"
+
+	"gorm.io/gorm/logger"
+)
+
+// traceLogger appends the trace ID of the request to GORM's SQL logs so
+// queries can be matched with the request that ran them
+type traceLogger struct {
+	logger.Interface
+}
+
+func (l traceLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return traceLogger{l.Interface.LogMode(level)}
+}
+
+func (l traceLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if tc, ok := tracer.FromContext(ctx); ok {
+		query := fc
+		fc = func() (string, int64) {
+			sql, rows := query()
+			return sql + " /* trace_id=" + tc.TraceID + " */", rows
+		}
+	}
+	l.Interface.Trace(ctx, begin, fc, err)
+}
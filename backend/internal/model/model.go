@@ -3,9 +3,11 @@ package model
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/pdfcache"
 	"curly-succotash/backend/migrations"
 	"curly-succotash/backend/pkg/setting"
 
@@ -14,6 +16,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 )
 
 // Model defines the common fields for all models
@@ -34,6 +37,8 @@ func (Model) TableName() string {
 // Game represents a board game entry
 type Game struct {
 	Model
+	ParentID    uint32    `gorm:"not null;default:0;index" json:"parent_id"` // forked from, 0 for originals
+	Visibility  string    `gorm:"type:varchar(16);not null;default:private;index" json:"visibility"`
 	Theme       string    `gorm:"type:text;not null" json:"theme"`
 	CardCount   int       `gorm:"column:card_count;not null" json:"card_count"`
 	Style       string    `gorm:"type:text;not null" json:"style"`
@@ -45,6 +50,16 @@ func (Game) TableName() string {
 	return "games"
 }
 
+// Game visibility. The owner is the user in CreatedBy.
+const (
+	// VisibilityPrivate games are only visible to their owner and share links
+	VisibilityPrivate = "private"
+	// VisibilityUnlisted games are visible by ID but not listed to others
+	VisibilityUnlisted = "unlisted"
+	// VisibilityPublic games are listed and visible to everyone
+	VisibilityPublic = "public"
+)
+
 // Card represents a card entry
 type Card struct {
 	Model
@@ -79,7 +94,7 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 	// Open database connection based on DBType
 	switch databaseSetting.DBType {
 	case "sqlite3":
-		db, err = gorm.Open(sqlite.Open(databaseSetting.Path+"?_foreign_keys=on"), &gorm.Config{})
+		db, err = gorm.Open(sqlite.Open(databaseSetting.Path+"?_foreign_keys=on"), &gorm.Config{Logger: traceLogger{logger.Default}})
 	case "mysql", "mariadb":
 		dsn := fmt.Sprintf(
 			"%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -88,7 +103,7 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 			databaseSetting.Host,
 			databaseSetting.DBName,
 		)
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: traceLogger{logger.Default}})
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", databaseSetting.DBType)
 	}
@@ -97,7 +112,7 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 	}
 
 	// Set GORM configurations
-	if global.AppSetting.RunMode == "debug" {
+	if global.AppSetting.Load().RunMode == "debug" {
 		db = db.Debug()
 	}
 
@@ -110,9 +125,16 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(databaseSetting.MaxOpenConns)
 
 	// Register callbacks
+	registerTracingCallbacks(db)
 	db.Callback().Create().Before("gorm:create").Register("app:update_time_stamp", updateTimeStampForCreateCallback)
 	db.Callback().Update().Before("gorm:update").Register("app:update_time_stamp", updateTimeStampForUpdateCallback)
 	db.Callback().Delete().Before("gorm:delete").Register("app:soft_delete", softDeleteCallback)
+	db.Callback().Create().After("gorm:create").Register("app:invalidate_pdf_cache", invalidatePDFCacheCallback)
+	db.Callback().Update().After("gorm:update").Register("app:invalidate_pdf_cache", invalidatePDFCacheCallback)
+	db.Callback().Delete().After("gorm:delete").Register("app:invalidate_pdf_cache", invalidatePDFCacheCallback)
+	db.Callback().Create().After("gorm:create").Register("app:record_revision", recordRevisionCallback(RevisionActionCreate))
+	db.Callback().Update().After("gorm:update").Register("app:record_revision", recordRevisionCallback(RevisionActionUpdate))
+	db.Callback().Delete().After("gorm:delete").Register("app:record_revision", recordRevisionCallback(RevisionActionDelete))
 
 	// Apply migrations
 	if err := applyMigrations(db); err != nil {
@@ -152,6 +174,26 @@ func applyMigrations(db *gorm.DB) error {
 	return nil
 }
 
+// PendingMigrations returns the IDs of the registered migrations that have
+// not been applied to db
+func PendingMigrations(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var applied []string
+	if err := db.WithContext(ctx).Table(gormigrate.DefaultOptions.TableName).Pluck(gormigrate.DefaultOptions.IDColumnName, &applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %s", err)
+	}
+	done := make(map[string]bool, len(applied))
+	for _, id := range applied {
+		done[id] = true
+	}
+	var pending []string
+	for _, m := range migrations.GetMigrations() {
+		if !done[m.ID] {
+			pending = append(pending, m.ID)
+		}
+	}
+	return pending, nil
+}
+
 // updateTimeStampForCreateCallback sets CreatedOn and ModifiedOn on create
 func updateTimeStampForCreateCallback(db *gorm.DB) {
 	if db.Error != nil {
@@ -175,6 +217,12 @@ func updateTimeStampForUpdateCallback(db *gorm.DB) {
 	if db.Error != nil {
 		return
 	}
+	if db.Statement.Schema == nil {
+		return
+	}
+	if _, ok := db.Statement.Schema.FieldsByName["ModifiedOn"]; !ok {
+		return
+	}
 	if _, ok := db.Statement.Context.Value("gorm:update_column").(bool); !ok {
 		db.Statement.SetColumn("ModifiedOn", uint32(time.Now().Unix()))
 	}
@@ -198,3 +246,60 @@ func softDeleteCallback(db *gorm.DB) {
 		}
 	}
 }
+
+// invalidatePDFCacheCallback removes cached exports of the games touched by a
+// statement. Cache keys already include the game content, so this only frees
+// stale files; statements without a model value (e.g. raw batch updates) are skipped.
+func invalidatePDFCacheCallback(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	var field string
+	switch db.Statement.Schema.Table {
+	case Game{}.TableName():
+		field = "ID"
+	case Card{}.TableName():
+		field = "GameID"
+	default:
+		return
+	}
+
+	for _, id := range statementIDs(db, field) {
+		if err := pdfcache.Invalidate(id); err != nil {
+			global.Logger.Warnf(db.Statement.Context, "failed to invalidate PDF cache of game %d: %s", id, err)
+		}
+	}
+}
+
+// statementIDs returns the non-zero values of a uint field of the model
+// values carried by a statement
+func statementIDs(db *gorm.DB, field string) []uint32 {
+	var ids []uint32
+	for _, v := range statementValues(db) {
+		if f := v.FieldByName(field); f.IsValid() && f.CanUint() && f.Uint() != 0 {
+			ids = append(ids, uint32(f.Uint()))
+		}
+	}
+	return ids
+}
+
+// statementValues returns the model structs carried by a statement, which
+// may be a single struct or a slice
+func statementValues(db *gorm.DB) []reflect.Value {
+	var values []reflect.Value
+	collect := func(v reflect.Value) {
+		if v = reflect.Indirect(v); v.Kind() == reflect.Struct {
+			values = append(values, v)
+		}
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(rv.Index(i))
+		}
+	default:
+		collect(rv)
+	}
+	return values
+}
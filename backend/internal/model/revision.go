@@ -0,0 +1,123 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Revision actions
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionDelete = "delete"
+)
+
+// Revision entity types
+const (
+	RevisionEntityGame = "game"
+	RevisionEntityCard = "card"
+)
+
+// Revision is a snapshot of a game or card taken after each change
+type Revision struct {
+	ID         uint32          `gorm:"primaryKey" json:"id"`
+	GameID     uint32          `gorm:"not null;index" json:"game_id"`
+	EntityType string          `gorm:"type:varchar(16);not null" json:"entity_type"`
+	EntityID   uint32          `gorm:"not null" json:"entity_id"`
+	Action     string          `gorm:"type:varchar(16);not null" json:"action"`
+	Snapshot   json.RawMessage `gorm:"type:text;not null" json:"snapshot"`
+	CreatedBy  string          `json:"created_by"`
+	CreatedOn  uint32          `json:"created_on"`
+}
+
+// TableName specifies the table name for Revision
+func (Revision) TableName() string {
+	return "revisions"
+}
+
+// recordRevisionCallback stores a snapshot of every game or card written by
+// a statement, read back within the same transaction so partial updates are
+// captured in full. The actor is the row's ModifiedBy. Statements without a
+// model value carrying the ID (e.g. raw batch updates) are not recorded.
+func recordRevisionCallback(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Schema == nil || db.RowsAffected == 0 {
+			return
+		}
+		var entityType string
+		switch db.Statement.Schema.Table {
+		case Game{}.TableName():
+			entityType = RevisionEntityGame
+		case Card{}.TableName():
+			entityType = RevisionEntityCard
+		default:
+			return
+		}
+
+		tx := db.Session(&gorm.Session{NewDB: true})
+		for _, v := range statementValues(db) {
+			id := uint32(v.FieldByName("ID").Uint())
+			if id == 0 {
+				continue
+			}
+			rev, err := snapshotRevision(tx, entityType, v)
+			if err != nil {
+				db.AddError(fmt.Errorf("failed to record revision of %s %d: %s", entityType, id, err))
+				return
+			}
+			rev.Action = action
+			// Updates that soft delete a row are recorded as deletes
+			if rev.deleted {
+				rev.Action = RevisionActionDelete
+			}
+			if err := tx.Create(&rev.Revision).Error; err != nil {
+				db.AddError(fmt.Errorf("failed to record revision of %s %d: %s", entityType, id, err))
+				return
+			}
+		}
+	}
+}
+
+type revisionSnapshot struct {
+	Revision
+	deleted bool
+}
+
+// snapshotRevision reads the current row of the entity held by v into a
+// revision. Rows that no longer exist are snapshotted from v.
+func snapshotRevision(tx *gorm.DB, entityType string, v reflect.Value) (revisionSnapshot, error) {
+	id := uint32(v.FieldByName("ID").Uint())
+	row := reflect.New(v.Type())
+	err := tx.Where("id = ?", id).First(row.Interface()).Error
+	gone := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !gone {
+		return revisionSnapshot{}, err
+	}
+	if gone {
+		row.Elem().Set(v)
+	}
+	current := row.Elem()
+
+	snapshot, err := json.Marshal(row.Interface())
+	if err != nil {
+		return revisionSnapshot{}, err
+	}
+	rev := revisionSnapshot{
+		Revision: Revision{
+			GameID:     id,
+			EntityType: entityType,
+			EntityID:   id,
+			Snapshot:   snapshot,
+			CreatedBy:  current.FieldByName("ModifiedBy").String(),
+		},
+		deleted: gone || current.FieldByName("IsDel").Uint() != 0,
+	}
+	if entityType == RevisionEntityCard {
+		rev.GameID = uint32(current.FieldByName("GameID").Uint())
+	}
+	return rev, nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"curly-succotash/backend/pkg/tracer"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanInstanceKey stores the span of a statement between its callbacks
+const spanInstanceKey = "app:span"
+
+// registerTracingCallbacks wraps every statement in a span named after its
+// operation, e.g. "gorm.query", started before and ended after the other
+// callbacks of the processor
+func registerTracingCallbacks(db *gorm.DB) {
+	cb := db.Callback()
+	cb.Create().Before("*").Register("app:start_span", startSpanCallback("create"))
+	cb.Create().After("*").Register("app:end_span", endSpanCallback)
+	cb.Query().Before("*").Register("app:start_span", startSpanCallback("query"))
+	cb.Query().After("*").Register("app:end_span", endSpanCallback)
+	cb.Update().Before("*").Register("app:start_span", startSpanCallback("update"))
+	cb.Update().After("*").Register("app:end_span", endSpanCallback)
+	cb.Delete().Before("*").Register("app:start_span", startSpanCallback("delete"))
+	cb.Delete().After("*").Register("app:end_span", endSpanCallback)
+	cb.Row().Before("*").Register("app:start_span", startSpanCallback("row"))
+	cb.Row().After("*").Register("app:end_span", endSpanCallback)
+	cb.Raw().Before("*").Register("app:start_span", startSpanCallback("raw"))
+	cb.Raw().After("*").Register("app:end_span", endSpanCallback)
+}
+
+// startSpanCallback starts the span of a statement as a child of the span
+// in its context
+func startSpanCallback(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := tracer.Start(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(
+			attribute.String("db.system", db.Dialector.Name()),
+			attribute.String("db.operation.name", operation),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+// endSpanCallback records the table, SQL, affected rows and error of a
+// statement on its span and ends it
+func endSpanCallback(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanInstanceKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	err := db.Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+	}
+	tracer.End(span, err)
+}
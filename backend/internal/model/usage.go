@@ -0,0 +1,22 @@
+package model
+
+// TokenUsage records the tokens and cost of an AI call. AuditLogID is the
+// audited request that made the call, and GameID the game it generated,
+// forked or regenerated, or 0 when the request failed before one existed.
+type TokenUsage struct {
+	ID           uint32  `gorm:"primaryKey" json:"id"`
+	GameID       uint32  `gorm:"not null;default:0;index" json:"game_id"`
+	AuditLogID   uint32  `gorm:"not null;default:0;index" json:"audit_log_id"`
+	Actor        string  `gorm:"type:varchar(64);index" json:"actor"`
+	Model        string  `gorm:"type:varchar(64);not null" json:"model"`
+	PromptTokens int64   `gorm:"not null;default:0" json:"prompt_tokens"`
+	OutputTokens int64   `gorm:"not null;default:0" json:"output_tokens"`
+	Cost         float64 `gorm:"not null;default:0" json:"cost"`
+	Day          string  `gorm:"type:varchar(10);not null;index" json:"day"` // UTC date, YYYY-MM-DD
+	CreatedOn    uint32  `gorm:"not null" json:"created_on"`
+}
+
+// TableName specifies the table name for TokenUsage
+func (TokenUsage) TableName() string {
+	return "token_usages"
+}
@@ -0,0 +1,13 @@
+package model
+
+// User is an account that signs in to the API
+type User struct {
+	Model
+	Username     string `gorm:"type:varchar(64);not null;uniqueIndex" json:"username"`
+	PasswordHash string `gorm:"type:varchar(255);not null" json:"-"`
+}
+
+// TableName specifies the table name for User
+func (User) TableName() string {
+	return "users"
+}
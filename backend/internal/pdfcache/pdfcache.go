@@ -0,0 +1,105 @@
+package pdfcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"curly-succotash/backend/global"
+)
+
+// Key hashes everything that influences a rendered export. Any change to
+// the game, its cards, the theme or the layout options yields a new key.
+func Key(parts ...interface{}) (string, error) {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, p := range parts {
+		if err := enc.Encode(p); err != nil {
+			return "", fmt.Errorf("failed to hash export content: %s", err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Path returns where the export of a game with the given key is stored
+func Path(gameID uint32, key, ext string) string {
+	return filepath.Join(folder(), fmt.Sprintf("game_%d_%s%s", gameID, key[:16], ext))
+}
+
+// File returns the path of a cached export of a game named name, as
+// returned by Path, when it exists. It serves exports by name to routes that
+// check the game's visibility.
+func File(gameID uint32, name string) (string, bool) {
+	if name != filepath.Base(name) || !strings.HasPrefix(name, fmt.Sprintf("game_%d_", gameID)) || strings.HasSuffix(name, ".tmp") {
+		return "", false
+	}
+	path := filepath.Join(folder(), name)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// Lookup returns the path of a cached export when it exists
+func Lookup(gameID uint32, key, ext string) (string, bool) {
+	path := Path(gameID, key, ext)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Store writes an export to the cache. The file is written under a
+// temporary name and renamed so concurrent readers never see a partial file.
+func Store(gameID uint32, key, ext string, data []byte) (string, error) {
+	if err := os.MkdirAll(folder(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %s", err)
+	}
+	path := Path(gameID, key, ext)
+	tmp, err := os.CreateTemp(folder(), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write export: %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write export: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store export: %s", err)
+	}
+	return path, nil
+}
+
+// Invalidate removes every cached export of a game
+func Invalidate(gameID uint32) error {
+	matches, err := filepath.Glob(filepath.Join(folder(), fmt.Sprintf("game_%d_*", gameID)))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cached export %s: %s", m, err)
+		}
+	}
+	return nil
+}
+
+// Dir is the subfolder of the PDF folder holding the cache. The /files route
+// does not serve it, as it holds exports of private games.
+const Dir = "cache"
+
+func folder() string {
+	storage := global.StoragePathSetting.Load()
+	if storage == nil || storage.PDFFoldar == "" {
+		return filepath.Join("files", Dir)
+	}
+	return filepath.Join(storage.PDFFoldar, Dir)
+}
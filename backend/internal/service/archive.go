@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/archive"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"gorm.io/gorm/clause"
+)
+
+// ExportGame builds a portable bundle of a game, its cards and meta values.
+// It returns gorm.ErrRecordNotFound when the game does not exist.
+func ExportGame(ctx context.Context, id string) (archive.Bundle, error) {
+	game, cards, err := GetGameWithCards(ctx, id)
+	if err != nil {
+		return archive.Bundle{}, err
+	}
+	metas, err := gameMetas(ctx, game.ID)
+	if err != nil {
+		return archive.Bundle{}, err
+	}
+	return archive.New(game, cards, metas, global.DBEngine.Dialector.Name()), nil
+}
+
+// ImportGame recreates a bundled game with new IDs in a single transaction
+// and returns the new game ID
+func ImportGame(ctx context.Context, b *archive.Bundle) (uint32, error) {
+	tx := global.DBEngine.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	cardCount := b.Game.CardCount
+	if cardCount == 0 {
+		cardCount = len(b.Cards)
+	}
+	actor := app.Actor(ctx)
+	createdAt := b.Game.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	game := model.Game{
+		Theme:       b.Game.Theme,
+		CardCount:   cardCount,
+		Style:       b.Game.Style,
+		Description: b.Game.Description,
+		Visibility:  model.VisibilityPrivate,
+		CreatedAt:   createdAt,
+		Model: model.Model{
+			CreatedBy:  actor,
+			ModifiedBy: actor,
+		},
+	}
+	if err := tx.Create(&game).Error; err != nil {
+		return 0, fmt.Errorf("failed to create game: %s", err)
+	}
+
+	for _, c := range b.Cards {
+		card := model.Card{
+			GameID:      game.ID,
+			Type:        c.Type,
+			Name:        c.Name,
+			Description: c.Description,
+			Effect:      c.Effect,
+			Model: model.Model{
+				CreatedBy:  actor,
+				ModifiedBy: actor,
+			},
+		}
+		if err := tx.Create(&card).Error; err != nil {
+			return 0, fmt.Errorf("failed to create card: %s", err)
+		}
+	}
+
+	prefix := archive.MetaPrefix(game.ID)
+	for key, value := range b.Meta {
+		meta := model.Meta{Key: prefix + key, Value: value}
+		if err := tx.Create(&meta).Error; err != nil {
+			return 0, fmt.Errorf("failed to create meta: %s", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit import: %s", err)
+	}
+	return game.ID, nil
+}
+
+// gameMetas returns the meta values keyed with the game's prefix
+func gameMetas(ctx context.Context, gameID uint32) ([]model.Meta, error) {
+	prefix := archive.MetaPrefix(gameID)
+	var candidates []model.Meta
+	// "_" is a LIKE wildcard, so the prefix is checked again below
+	like := clause.Like{Column: clause.Column{Name: "key"}, Value: prefix + "%"}
+	if err := global.DBEngine.WithContext(ctx).Where(like).Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch meta: %s", err)
+	}
+	metas := candidates[:0]
+	for _, m := range candidates {
+		if strings.HasPrefix(m.Key, prefix) {
+			metas = append(metas, m)
+		}
+	}
+	return metas, nil
+}
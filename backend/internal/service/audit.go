@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Audit query limits
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// AuditFilter selects audit log entries. Empty fields match everything;
+// From and To bound the creation time, inclusive and exclusive.
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditOutcome returns the outcome recorded for a response status
+func AuditOutcome(status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return model.AuditOutcomeError
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return model.AuditOutcomeDenied
+	case status >= http.StatusBadRequest:
+		return model.AuditOutcomeInvalid
+	}
+	return model.AuditOutcomeSuccess
+}
+
+// RecordAudit stores an audit log entry, timestamped now, together with the
+// token usage of the AI calls made by the request
+func RecordAudit(ctx context.Context, entry *model.AuditLog, calls []ai.Call) error {
+	entry.CreatedOn = uint32(time.Now().Unix())
+	return global.DBEngine.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to record audit log: %s", err)
+		}
+		if len(calls) == 0 {
+			return nil
+		}
+		if err := recordUsage(ctx, tx, entry, calls); err != nil {
+			return err
+		}
+		return tx.Model(entry).Update("cost", entry.Cost).Error
+	})
+}
+
+// ListAuditLogs returns the entries matching f, newest first, and the number
+// of entries matching f regardless of its limit and offset
+func ListAuditLogs(ctx context.Context, f AuditFilter) ([]model.AuditLog, int64, error) {
+	db := global.DBEngine.WithContext(ctx)
+	var total int64
+	if err := auditQuery(db, f).Model(&model.AuditLog{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %s", err)
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultAuditLimit
+	}
+	var logs []model.AuditLog
+	if err := auditQuery(db, f).Order("id DESC").Limit(f.Limit).Offset(f.Offset).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit logs: %s", err)
+	}
+	return logs, total, nil
+}
+
+// ExportAuditLogs writes every entry matching f, ignoring its limit and
+// offset, as CSV with a header row, newest first
+func ExportAuditLogs(ctx context.Context, w io.Writer, f AuditFilter) error {
+	rows, err := auditQuery(global.DBEngine.WithContext(ctx), f).Model(&model.AuditLog{}).Order("id DESC").Rows()
+	if err != nil {
+		return fmt.Errorf("failed to fetch audit logs: %s", err)
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+	header := []string{"id", "time", "action", "actor", "ip", "remote_ip", "route", "target_type", "target_id", "params", "status", "outcome", "prompt_tokens", "output_tokens", "cost"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for rows.Next() {
+		var entry model.AuditLog
+		if err := global.DBEngine.ScanRows(rows, &entry); err != nil {
+			return fmt.Errorf("failed to read audit log: %s", err)
+		}
+		err := cw.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			time.Unix(int64(entry.CreatedOn), 0).UTC().Format(time.RFC3339),
+			csvCell(entry.Action),
+			csvCell(entry.Actor),
+			csvCell(entry.IP),
+			csvCell(entry.RemoteIP),
+			csvCell(entry.Route),
+			csvCell(entry.TargetType),
+			csvCell(entry.TargetID),
+			csvCell(string(entry.Params)),
+			strconv.Itoa(entry.Status),
+			entry.Outcome,
+			strconv.FormatInt(entry.PromptTokens, 10),
+			strconv.FormatInt(entry.OutputTokens, 10),
+			strconv.FormatFloat(entry.Cost, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch audit logs: %s", err)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell keeps spreadsheets from evaluating a cell as a formula by
+// prefixing values starting with =, +, -, @, a tab or a carriage return
+// with a single quote
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func auditQuery(db *gorm.DB, f AuditFilter) *gorm.DB {
+	if f.Action != "" {
+		db = db.Where("action = ?", f.Action)
+	}
+	if f.Actor != "" {
+		db = db.Where("actor = ?", f.Actor)
+	}
+	if f.TargetType != "" {
+		db = db.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		db = db.Where("target_id = ?", f.TargetID)
+	}
+	if f.Outcome != "" {
+		db = db.Where("outcome = ?", f.Outcome)
+	}
+	if !f.From.IsZero() {
+		db = db.Where("created_on >= ?", f.From.Unix())
+	}
+	if !f.To.IsZero() {
+		db = db.Where("created_on < ?", f.To.Unix())
+	}
+	return db
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// useTestDB points global.DBEngine at a new SQLite database with the
+// tables of models for the duration of the test
+func useTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	previous := global.DBEngine
+	global.DBEngine = db
+	t.Cleanup(func() { global.DBEngine = previous })
+	return db
+}
+
+// useTestJWT signs tokens with a test secret for the duration of the test
+func useTestJWT(t *testing.T) {
+	t.Helper()
+	previous := global.JWTSetting.Load()
+	global.JWTSetting.Store(&setting.JWTSettingS{Secret: "test-secret", Issuer: "test", Expire: time.Hour})
+	t.Cleanup(func() { global.JWTSetting.Store(previous) })
+}
+
+// issueTestToken issues and parses an access token for an AppKey
+func issueTestToken(t *testing.T, ctx context.Context, appKey, secret string) *app.Claims {
+	t.Helper()
+	token, err := IssueAppToken(ctx, appKey, secret)
+	if err != nil {
+		t.Fatalf("IssueAppToken: %v", err)
+	}
+	claims, err := app.ParseToken(token.AccessToken, app.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	return claims
+}
+
+func TestCheckAppToken(t *testing.T) {
+	db := useTestDB(t, &model.Auth{})
+	useTestJWT(t)
+	ctx := app.WithActor(context.Background(), "alice")
+
+	auth, secret, err := CreateAppKey(ctx, "ci", []string{model.ScopeGamesRead})
+	if err != nil {
+		t.Fatalf("CreateAppKey: %v", err)
+	}
+	before := issueTestToken(t, ctx, auth.AppKey, secret)
+	if err := CheckAppToken(ctx, before); err != nil {
+		t.Fatalf("CheckAppToken of a new token: %v", err)
+	}
+	if before.KeyVersion != 0 || before.Username != "alice" {
+		t.Errorf("claims = %+v, want version 0 acting for alice", before)
+	}
+
+	rotated, newSecret, err := RotateAppKey(ctx, auth.ID)
+	if err != nil {
+		t.Fatalf("RotateAppKey: %v", err)
+	}
+	// Rotations within the second the token was issued in must revoke it too
+	if err := db.Model(&model.Auth{}).Where("id = ?", auth.ID).Update("rotated_on", uint32(before.IssuedAt.Unix())).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckAppToken(ctx, before); !errors.Is(err, ErrAppKeyNotFound) {
+		t.Errorf("CheckAppToken of a token issued before a rotation in the same second = %v, want %v", err, ErrAppKeyNotFound)
+	}
+	if _, err := IssueAppToken(ctx, auth.AppKey, secret); !errors.Is(err, ErrAppKeyNotFound) {
+		t.Errorf("IssueAppToken with the old secret = %v, want %v", err, ErrAppKeyNotFound)
+	}
+
+	after := issueTestToken(t, ctx, auth.AppKey, newSecret)
+	if after.KeyVersion != rotated.KeyVersion || after.KeyVersion != 1 {
+		t.Errorf("version of a token issued after the rotation = %d, want 1", after.KeyVersion)
+	}
+	if err := CheckAppToken(ctx, after); err != nil {
+		t.Errorf("CheckAppToken of a token issued after the rotation: %v", err)
+	}
+
+	if err := RevokeAppKey(ctx, auth.ID); err != nil {
+		t.Fatalf("RevokeAppKey: %v", err)
+	}
+	if err := CheckAppToken(ctx, after); !errors.Is(err, ErrAppKeyNotFound) {
+		t.Errorf("CheckAppToken after revoking = %v, want %v", err, ErrAppKeyNotFound)
+	}
+}
+
+func TestCheckAppTokenBeforeKeyVersions(t *testing.T) {
+	db := useTestDB(t, &model.Auth{})
+	ctx := app.WithActor(context.Background(), "alice")
+	auth := model.Auth{AppKey: "ak_test", SecretHash: hashSecret("s"), Scopes: model.ScopeGamesRead, RotatedOn: 1000}
+	if err := db.Create(&auth).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens issued before key versions carry version 0 and are checked by time
+	tests := []struct {
+		issuedAt int64
+		wantErr  bool
+	}{
+		{999, true},
+		{1000, false},
+		{1001, false},
+	}
+	for _, tt := range tests {
+		claims := &app.Claims{AppKey: "ak_test"}
+		claims.IssuedAt = jwtTime(tt.issuedAt)
+		if err := CheckAppToken(ctx, claims); (err != nil) != tt.wantErr {
+			t.Errorf("CheckAppToken of a token issued at %d = %v, want error %v", tt.issuedAt, err, tt.wantErr)
+		}
+	}
+	if err := CheckAppToken(ctx, &app.Claims{AppKey: "ak_test"}); err == nil {
+		t.Error("CheckAppToken of a token without iat succeeded")
+	}
+	if err := CheckAppToken(ctx, &app.Claims{AppKey: "ak_unknown"}); !errors.Is(err, ErrAppKeyNotFound) {
+		t.Errorf("CheckAppToken of an unknown key = %v, want %v", err, ErrAppKeyNotFound)
+	}
+}
+
+func jwtTime(unix int64) *jwt.NumericDate {
+	return jwt.NewNumericDate(time.Unix(unix, 0))
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/cardtable"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+)
+
+// ApplyCardEdits updates existing cards of a game from CSV edits. Edits
+// referring to cards outside the game are added to rowErrs, the errors
+// found while parsing; nothing is written unless every row is valid. It
+// returns the number of cards whose content changed.
+func ApplyCardEdits(ctx context.Context, gameID uint32, edits []cardtable.Edit, rowErrs []cardtable.RowError) (int, []cardtable.RowError, error) {
+	tx := global.DBEngine.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var cards []model.Card
+	if err := tx.Where("game_id = ? AND is_del = 0", gameID).Find(&cards).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch cards: %s", err)
+	}
+	byID := make(map[uint32]model.Card, len(cards))
+	for _, card := range cards {
+		byID[card.ID] = card
+	}
+
+	var changed []model.Card
+	for _, edit := range edits {
+		card, ok := byID[edit.CardID]
+		if !ok {
+			rowErrs = append(rowErrs, cardtable.RowError{Row: edit.Row, Column: string(cardtable.ColumnID), Message: fmt.Sprintf("card %d does not belong to game %d", edit.CardID, gameID)})
+			continue
+		}
+		updated := card
+		for col, value := range edit.Fields {
+			switch col {
+			case cardtable.ColumnType:
+				updated.Type = value
+			case cardtable.ColumnName:
+				updated.Name = value
+			case cardtable.ColumnDescription:
+				updated.Description = value
+			case cardtable.ColumnEffect:
+				updated.Effect = value
+			}
+		}
+		if updated != card {
+			changed = append(changed, updated)
+		}
+	}
+	if len(rowErrs) > 0 {
+		sort.SliceStable(rowErrs, func(i, j int) bool { return rowErrs[i].Row < rowErrs[j].Row })
+		return 0, rowErrs, nil
+	}
+
+	for _, card := range changed {
+		card.ModifiedBy = app.Actor(ctx)
+		if err := tx.Model(&card).Select("type", "name", "description", "effect", "modified_by", "modified_on").Updates(&card).Error; err != nil {
+			return 0, nil, fmt.Errorf("failed to update card %d: %s", card.ID, err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to commit card edits: %s", err)
+	}
+	return len(changed), nil, nil
+}
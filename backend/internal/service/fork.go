@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/archive"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/metrics"
+)
+
+// forkBatchSize is the number of cards rewritten per AI call
+const forkBatchSize = 10
+
+var (
+	// ErrAIGenerate indicates the AI service failed to generate content
+	ErrAIGenerate = errors.New("AI generation failed")
+	// ErrAIResponse indicates the AI returned content that could not be used
+	ErrAIResponse = errors.New("invalid AI response")
+)
+
+// ContentGenerator produces text for a prompt, such as *ai.GeminiClient
+type ContentGenerator interface {
+	GenerateContent(ctx context.Context, prompt string) (string, error)
+}
+
+// ForkOptions customizes a forked game. Empty fields keep the parent's values.
+type ForkOptions struct {
+	Instruction string
+	Theme       string
+	Style       string
+}
+
+// ForkGame copies a game, its cards and meta values into a new game whose
+// ParentID records the source. When opts.Instruction is set, gen rewrites the
+// story and every card before anything is written; the source game is never
+// modified. The fork is private and owned by the request's user. It returns
+// gorm.ErrRecordNotFound when the game does not exist or is not visible.
+func ForkGame(ctx context.Context, id string, opts ForkOptions, gen ContentGenerator) (model.Game, error) {
+	parent, cards, err := GetGameWithCards(ctx, id)
+	if err != nil {
+		return model.Game{}, err
+	}
+	metas, err := gameMetas(ctx, parent.ID)
+	if err != nil {
+		return model.Game{}, err
+	}
+
+	description := parent.Description
+	if opts.Instruction != "" {
+		if gen == nil {
+			return model.Game{}, errors.New("an AI client is required to apply an instruction")
+		}
+		start := time.Now()
+		description, err = transformStory(ctx, gen, opts.Instruction, description)
+		metrics.ObserveStep("fork_story", start, err)
+		if err != nil {
+			return model.Game{}, err
+		}
+		start = time.Now()
+		cards, err = transformCards(ctx, gen, opts.Instruction, description, cards)
+		metrics.ObserveStep("fork_cards", start, err)
+		if err != nil {
+			return model.Game{}, err
+		}
+	}
+
+	actor := app.Actor(ctx)
+	now := uint32(time.Now().Unix())
+	game := model.Game{
+		ParentID:    parent.ID,
+		Visibility:  model.VisibilityPrivate,
+		Theme:       firstNonEmpty(opts.Theme, parent.Theme),
+		CardCount:   parent.CardCount,
+		Style:       firstNonEmpty(opts.Style, parent.Style),
+		Description: description,
+		CreatedAt:   time.Now(),
+		Model: model.Model{
+			CreatedBy:  actor,
+			ModifiedBy: actor,
+			CreatedOn:  now,
+			ModifiedOn: now,
+		},
+	}
+
+	tx := global.DBEngine.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := tx.Create(&game).Error; err != nil {
+		return model.Game{}, fmt.Errorf("failed to create game: %s", err)
+	}
+	for _, c := range cards {
+		card := model.Card{
+			GameID:      game.ID,
+			Type:        c.Type,
+			Name:        c.Name,
+			Description: c.Description,
+			Effect:      c.Effect,
+			Model: model.Model{
+				CreatedBy:  actor,
+				ModifiedBy: actor,
+				CreatedOn:  now,
+				ModifiedOn: now,
+			},
+		}
+		if err := tx.Create(&card).Error; err != nil {
+			return model.Game{}, fmt.Errorf("failed to create card: %s", err)
+		}
+	}
+
+	parentPrefix, prefix := archive.MetaPrefix(parent.ID), archive.MetaPrefix(game.ID)
+	for _, m := range metas {
+		meta := model.Meta{Key: prefix + strings.TrimPrefix(m.Key, parentPrefix), Value: m.Value}
+		if err := tx.Create(&meta).Error; err != nil {
+			return model.Game{}, fmt.Errorf("failed to create meta: %s", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return model.Game{}, fmt.Errorf("failed to commit fork: %s", err)
+	}
+	if opts.Instruction != "" {
+		for _, c := range cards {
+			metrics.ObserveCard(c.Type)
+		}
+	}
+	return game, nil
+}
+
+// transformStory rewrites a story background according to the instruction
+func transformStory(ctx context.Context, gen ContentGenerator, instruction, story string) (string, error) {
+	text, err := gen.GenerateContent(ctx, fmt.Sprintf(global.TransformStoryPrompt, instruction, story))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to transform story: %s", ErrAIGenerate, err)
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		return "", fmt.Errorf("%w: failed to parse story JSON: %s", ErrAIResponse, err)
+	}
+	if result["story_background"] == "" {
+		return "", fmt.Errorf("%w: transformed story background is empty", ErrAIResponse)
+	}
+	return result["story_background"], nil
+}
+
+// forkCard is the card shape exchanged with the AI when transforming cards
+type forkCard struct {
+	ID          uint32 `json:"id"`
+	Type        string `json:"type,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Effect      string `json:"effect"`
+}
+
+// transformCards rewrites the cards in batches, keeping each card's type.
+// It fails if the AI drops or invents a card so forks keep the parent's deck.
+func transformCards(ctx context.Context, gen ContentGenerator, instruction, story string, cards []model.Card) ([]model.Card, error) {
+	out := make([]model.Card, 0, len(cards))
+	for start := 0; start < len(cards); start += forkBatchSize {
+		batch := cards[start:min(start+forkBatchSize, len(cards))]
+		input := make([]forkCard, len(batch))
+		for i, c := range batch {
+			input[i] = forkCard{ID: c.ID, Type: c.Type, Name: c.Name, Description: c.Description, Effect: c.Effect}
+		}
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cards: %s", err)
+		}
+
+		text, err := gen.GenerateContent(ctx, fmt.Sprintf(global.TransformCardsPrompt, instruction, story, data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to transform cards: %s", ErrAIGenerate, err)
+		}
+		var result []forkCard
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse cards JSON: %s", ErrAIResponse, err)
+		}
+		byID := make(map[uint32]forkCard, len(result))
+		for _, r := range result {
+			byID[r.ID] = r
+		}
+
+		for _, c := range batch {
+			r, ok := byID[c.ID]
+			if !ok || strings.TrimSpace(r.Name) == "" {
+				return nil, fmt.Errorf("%w: transformed cards are missing card %d", ErrAIResponse, c.ID)
+			}
+			c.Name, c.Description, c.Effect = r.Name, r.Description, r.Effect
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
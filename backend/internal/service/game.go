@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
@@ -8,11 +9,29 @@ import (
 
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/metrics"
+	"curly-succotash/backend/pkg/tracer"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jung-kurt/gofpdf"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// GetGameWithCards loads a game visible to the request, as GetGame does,
+// together with its cards ordered by ID
+func GetGameWithCards(ctx context.Context, id string) (model.Game, []model.Card, error) {
+	game, err := GetGame(ctx, id)
+	if err != nil {
+		return game, nil, err
+	}
+	var cards []model.Card
+	if err := global.DBEngine.WithContext(ctx).Where("game_id = ? AND is_del = 0", game.ID).Order("id").Find(&cards).Error; err != nil {
+		return game, nil, fmt.Errorf("failed to fetch cards: %s", err)
+	}
+	return game, cards, nil
+}
+
 // generateCards simulates AI card generation (to be replaced with Hugging Face model)
 func GenerateCards(c *gin.Context, input model.Game) ([]model.Card, error) {
 	cardTemplates := []model.Card{
@@ -34,48 +53,68 @@ func GenerateCards(c *gin.Context, input model.Game) ([]model.Card, error) {
 	return cards, nil
 }
 
-// generatePDF creates a PDF with card details
-func GeneratePDF(c *gin.Context, cards []model.Card) (string, error) {
+// generatePDF creates a PDF with card details laid out according to the theme
+func GeneratePDF(c *gin.Context, cards []model.Card, t *theme.Theme) (pdfPath string, err error) {
+	_, span := tracer.Start(c.Request.Context(), "pdf.gofpdf")
+	span.SetAttributes(
+		attribute.String("pdf.theme", t.Name),
+		attribute.Int("pdf.card_count", len(cards)),
+	)
+	start := time.Now()
+	defer func() {
+		metrics.PDFRenderDuration.WithLabelValues("gofpdf", metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+		tracer.End(span, err)
+	}()
+
 	// Ensure files directory exists
 	if err := os.MkdirAll("./files", 0755); err != nil {
 		return "", fmt.Errorf("failed to create files directory: %s", err)
 	}
 
 	// Generate unique PDF path
-	pdfPath := fmt.Sprintf("./%s/game_%d.pdf", global.StoragePathSetting.PDFFoldar, time.Now().UnixNano())
+	pdfPath = fmt.Sprintf("./%s/game_%d.pdf", global.StoragePathSetting.Load().PDFFoldar, time.Now().UnixNano())
 
 	// Initialize PDF
 	pdf := gofpdf.New("P", "mm", "A4", "")
+	global.Fonts.RegisterPDF(pdf)
 	pdf.AddPage()
 
-	// Set card dimensions (standard poker card: 63.5mm x 88.9mm)
-	cardWidth, cardHeight := 63.5, 88.9
-	cardsPerRow, cardsPerCol := 3, 2
-	margin := 10.0
+	// Card dimensions and grid come from the theme layout
+	layout := t.Layout
+	cardWidth, cardHeight := layout.CardWidth, layout.CardHeight
+	cardsPerRow, cardsPerCol := layout.CardsPerRow, layout.CardsPerCol
+	margin, gap := layout.Margin, layout.Gap
+	bgR, bgG, bgB := theme.RGB(t.Background)
+	borderR, borderG, borderB := theme.RGB(t.Border)
+	textR, textG, textB := theme.RGB(t.Text)
 
 	for i, card := range cards {
 		// Calculate card position
 		row := (i / cardsPerRow) % cardsPerCol
 		col := i % cardsPerRow
-		x := margin + float64(col)*(cardWidth+5)
-		y := margin + float64(row)*(cardHeight+5)
+		x := margin + float64(col)*(cardWidth+gap)
+		y := margin + float64(row)*(cardHeight+gap)
 
 		// Draw card border
-		pdf.SetFillColor(255, 255, 255) // White background
-		pdf.SetDrawColor(0, 0, 0)       // Black border
+		pdf.SetFillColor(bgR, bgG, bgB)
+		pdf.SetDrawColor(borderR, borderG, borderB)
 		pdf.Rect(x, y, cardWidth, cardHeight, "FD")
 
-		// Draw card content
-		pdf.SetTextColor(0, 0, 0) // Black text
-		pdf.SetFont("Arial", "B", 12)
-		pdf.Text(x+5, y+15, card.Name)
-
-		pdf.SetFont("Arial", "", 10)
-		pdf.SetXY(x+5, y+20)
-		pdf.MultiCell(cardWidth-10, 5, card.Description, "", "L", false)
+		// Draw the type accent bar
+		style := t.Style(card.Type)
+		accentR, accentG, accentB := theme.RGB(style.Color)
+		pdf.SetFillColor(accentR, accentG, accentB)
+		pdf.Rect(x, y, cardWidth, 4, "F")
 
-		pdf.SetXY(x+5, y+40)
-		pdf.MultiCell(cardWidth-10, 5, "Effect: "+card.Effect, "", "L", false)
+		// Draw card content
+		pdf.SetTextColor(textR, textG, textB)
+		name := card.Name
+		if style.Icon != "" {
+			name = style.Icon + " " + name
+		}
+		global.Fonts.MultiCellPDF(pdf, x+5, y+10, cardWidth-10, "B", 12, 6, name)
+		global.Fonts.MultiCellPDF(pdf, x+5, y+20, cardWidth-10, "", 10, 5, card.Description)
+		global.Fonts.MultiCellPDF(pdf, x+5, y+40, cardWidth-10, "", 10, 5, "Effect: "+card.Effect)
 
 		// Add new page if 6 cards are filled
 		if (i+1)%(cardsPerRow*cardsPerCol) == 0 && i < len(cards)-1 {
@@ -84,15 +123,19 @@ func GeneratePDF(c *gin.Context, cards []model.Card) (string, error) {
 	}
 
 	// Save PDF
-	err := pdf.OutputFileAndClose(pdfPath)
-	if err != nil {
+	if err := pdf.OutputFileAndClose(pdfPath); err != nil {
 		return "", fmt.Errorf("failed to save PDF: %s", err)
 	}
 
 	// Verify PDF exists
-	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
+	info, err := os.Stat(pdfPath)
+	if os.IsNotExist(err) {
 		return "", fmt.Errorf("PDF file not generated: %s", pdfPath)
 	}
+	if err == nil {
+		span.SetAttributes(attribute.Int64("pdf.size", info.Size()))
+		metrics.PDFSize.WithLabelValues("gofpdf").Observe(float64(info.Size()))
+	}
 
 	return pdfPath, nil
 }
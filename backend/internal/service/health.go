@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
+)
+
+// Check statuses
+const (
+	CheckOK    = "ok"
+	CheckError = "error"
+)
+
+// readinessTimeout bounds the time spent on the checks of a readiness probe
+const readinessTimeout = 3 * time.Second
+
+// readinessCacheTTL is how long CachedReadiness reuses a result, so that
+// unauthenticated probes cannot make the server check its dependencies on
+// every request
+const readinessCacheTTL = 5 * time.Second
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Readiness reports whether the service can handle requests
+type Readiness struct {
+	Ready  bool                   `json:"ready"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Statuses returns the readiness without the errors and durations of the
+// checks, which may reveal paths and configuration to anonymous callers
+func (r Readiness) Statuses() Readiness {
+	checks := make(map[string]CheckResult, len(r.Checks))
+	for name, check := range r.Checks {
+		checks[name] = CheckResult{Status: check.Status}
+	}
+	return Readiness{Ready: r.Ready, Checks: checks}
+}
+
+// readinessChecks are run by CheckReadiness, keyed by the name reported
+var readinessChecks = map[string]func(ctx context.Context) error{
+	"database":    checkDatabase,
+	"migrations":  checkMigrations,
+	"pdf_folder":  checkPDFFolder,
+	"wkhtmltopdf": checkWkhtmltopdf,
+	"ai":          checkAI,
+}
+
+// CheckReadiness runs every readiness check concurrently. The service is
+// ready when all of them pass.
+func CheckReadiness(ctx context.Context) Readiness {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		CheckResult
+	}
+	results := make(chan result, len(readinessChecks))
+	for name, check := range readinessChecks {
+		go func(name string, check func(context.Context) error) {
+			start := time.Now()
+			r := result{name: name, CheckResult: CheckResult{Status: CheckOK}}
+			if err := check(ctx); err != nil {
+				r.Status, r.Error = CheckError, err.Error()
+			}
+			r.Duration = time.Since(start).String()
+			results <- r
+		}(name, check)
+	}
+
+	readiness := Readiness{Ready: true, Checks: make(map[string]CheckResult, len(readinessChecks))}
+	for range readinessChecks {
+		r := <-results
+		readiness.Checks[r.name] = r.CheckResult
+		if r.Status != CheckOK {
+			readiness.Ready = false
+		}
+	}
+	return readiness
+}
+
+var readinessCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	readiness Readiness
+}
+
+// CachedReadiness returns the result of CheckReadiness, running the checks
+// at most once every readinessCacheTTL. Concurrent callers wait for the same
+// run, which is not canceled with the context of the caller that started it.
+// The result is shared and must not be modified.
+func CachedReadiness(ctx context.Context) Readiness {
+	readinessCache.mu.Lock()
+	defer readinessCache.mu.Unlock()
+	if time.Since(readinessCache.checkedAt) >= readinessCacheTTL {
+		readinessCache.readiness = CheckReadiness(context.WithoutCancel(ctx))
+		readinessCache.checkedAt = time.Now()
+	}
+	return readinessCache.readiness
+}
+
+// checkDatabase pings the database through the storage engine
+func checkDatabase(ctx context.Context) error {
+	if global.StorageEngine == nil {
+		return errors.New("storage engine not initialized")
+	}
+	return global.StorageEngine.Ping(ctx)
+}
+
+// checkMigrations fails while registered migrations are not applied
+func checkMigrations(ctx context.Context) error {
+	pending, err := model.PendingMigrations(ctx, global.DBEngine)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkPDFFolder verifies exported files can be written
+func checkPDFFolder(ctx context.Context) error {
+	dir := global.StoragePathSetting.Load().PDFFoldar
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %s", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %s", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkWkhtmltopdf verifies the wkhtmltopdf binary used for PDF exports is
+// installed
+func checkWkhtmltopdf(ctx context.Context) error {
+	if _, err := wkhtmltopdf.NewPDFGenerator(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkAI verifies the AI provider is configured
+func checkAI(ctx context.Context) error {
+	s := global.AISetting.Load()
+	if s.Model == "" {
+		return errors.New("AI.Model is not set")
+	}
+	apiKey, _, err := setting.LookupEnv(s.APIKey)
+	if err != nil {
+		return err
+	}
+	if apiKey == "" {
+		return fmt.Errorf("%s is not set", s.APIKey)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"gorm.io/gorm/clause"
+)
+
+// ErrQuotaExceeded indicates the caller used up today's generations
+var ErrQuotaExceeded = errors.New("daily generation quota exceeded")
+
+// Quota describes the daily generation quota of a caller after a request
+type Quota struct {
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// ConsumeGenerationQuota counts one AI generation against the daily quota of
+// the request's user. Counters live in the meta table, one row per user and
+// UTC day, and are only incremented below the limit so concurrent requests
+// cannot overshoot. A limit of 0 disables the quota.
+func ConsumeGenerationQuota(ctx context.Context) (Quota, error) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	quota := Quota{
+		Limit:    global.LimiterSetting.Load().DailyGenerations,
+		ResetsAt: day.AddDate(0, 0, 1),
+	}
+	if quota.Limit <= 0 {
+		return quota, nil
+	}
+
+	key := quotaKey(ctx, day)
+	db := global.DBEngine.WithContext(ctx)
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Meta{Key: key}).Error
+	if err != nil {
+		return quota, err
+	}
+
+	result := db.Model(&model.Meta{}).
+		Where("? = ? AND value < ?", clause.Column{Name: "key"}, key, quota.Limit).
+		Update("value", clause.Expr{SQL: "value + 1"})
+	if result.Error != nil {
+		return quota, result.Error
+	}
+
+	var meta model.Meta
+	if err := db.Where("? = ?", clause.Column{Name: "key"}, key).First(&meta).Error; err != nil {
+		return quota, err
+	}
+	quota.Remaining = max(quota.Limit-meta.Value, 0)
+	if result.RowsAffected == 0 {
+		return quota, ErrQuotaExceeded
+	}
+	return quota, nil
+}
+
+// RefundGenerationQuota gives back a generation counted by
+// ConsumeGenerationQuota, so failed generations do not use up the quota
+func RefundGenerationQuota(ctx context.Context) error {
+	if global.LimiterSetting.Load().DailyGenerations <= 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return global.DBEngine.WithContext(ctx).Model(&model.Meta{}).
+		Where("? = ? AND value > 0", clause.Column{Name: "key"}, quotaKey(ctx, day)).
+		Update("value", clause.Expr{SQL: "value - 1"}).Error
+}
+
+// quotaKey is the meta key counting the generations of the request's user on day
+func quotaKey(ctx context.Context, day time.Time) string {
+	return fmt.Sprintf("quota_generate_%s_%s", app.Actor(ctx), day.Format("20060102"))
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/setting"
+)
+
+// useTestQuota sets the daily generation quota for the duration of the test
+func useTestQuota(t *testing.T, limit int64) {
+	t.Helper()
+	previous := global.LimiterSetting.Load()
+	global.LimiterSetting.Store(&setting.LimiterSettingS{Store: "memory", DailyGenerations: limit})
+	t.Cleanup(func() { global.LimiterSetting.Store(previous) })
+}
+
+func TestGenerationQuota(t *testing.T) {
+	useTestDB(t, &model.Meta{})
+	useTestQuota(t, 2)
+	alice := app.WithActor(context.Background(), "alice")
+	bob := app.WithActor(context.Background(), "bob")
+
+	steps := []struct {
+		name          string
+		ctx           context.Context
+		refund        bool
+		wantRemaining int64
+		wantErr       error
+	}{
+		{"first", alice, false, 1, nil},
+		{"second", alice, false, 0, nil},
+		{"over the limit", alice, false, 0, ErrQuotaExceeded},
+		{"refunded", alice, true, 1, nil},
+		{"after the refund", alice, false, 0, nil},
+		{"still over the limit", alice, false, 0, ErrQuotaExceeded},
+		{"other user", bob, false, 1, nil},
+	}
+	for _, step := range steps {
+		if step.refund {
+			if err := RefundGenerationQuota(step.ctx); err != nil {
+				t.Fatalf("%s: RefundGenerationQuota: %v", step.name, err)
+			}
+			continue
+		}
+		quota, err := ConsumeGenerationQuota(step.ctx)
+		if !errors.Is(err, step.wantErr) {
+			t.Errorf("%s: ConsumeGenerationQuota() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if quota.Limit != 2 || quota.Remaining != step.wantRemaining {
+			t.Errorf("%s: quota = %+v, want limit 2 and %d remaining", step.name, quota, step.wantRemaining)
+		}
+	}
+}
+
+func TestGenerationQuotaRefundFloor(t *testing.T) {
+	db := useTestDB(t, &model.Meta{})
+	useTestQuota(t, 2)
+	ctx := app.WithActor(context.Background(), "alice")
+
+	if _, err := ConsumeGenerationQuota(ctx); err != nil {
+		t.Fatalf("ConsumeGenerationQuota: %v", err)
+	}
+	for range 2 {
+		if err := RefundGenerationQuota(ctx); err != nil {
+			t.Fatalf("RefundGenerationQuota: %v", err)
+		}
+	}
+	var metas []model.Meta
+	if err := db.Find(&metas).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(metas) != 1 || metas[0].Value != 0 {
+		t.Errorf("counters after refunding more than was used = %+v, want one at 0", metas)
+	}
+}
+
+func TestGenerationQuotaDisabled(t *testing.T) {
+	// No tables: a disabled quota must not touch the database
+	useTestDB(t)
+	useTestQuota(t, 0)
+	ctx := app.WithActor(context.Background(), "alice")
+
+	for range 3 {
+		if quota, err := ConsumeGenerationQuota(ctx); err != nil || quota.Limit != 0 {
+			t.Fatalf("ConsumeGenerationQuota() = %+v, %v, want an unlimited quota", quota, err)
+		}
+	}
+	if err := RefundGenerationQuota(ctx); err != nil {
+		t.Errorf("RefundGenerationQuota() = %v, want no error", err)
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"gorm.io/gorm"
+)
+
+// ErrRevisionNotFound indicates a revision that does not belong to the game
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// FieldChange is a field whose value differs between two revisions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Card change kinds
+const (
+	CardAdded    = "added"
+	CardRemoved  = "removed"
+	CardModified = "modified"
+)
+
+// CardDiff describes how a card differs between two revisions
+type CardDiff struct {
+	CardID uint32        `json:"card_id"`
+	Name   string        `json:"name"`
+	Change string        `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// RevisionDiff is the difference between the game states at two revisions
+type RevisionDiff struct {
+	GameID uint32        `json:"game_id"`
+	From   uint32        `json:"from"`
+	To     uint32        `json:"to"`
+	Game   []FieldChange `json:"game"`
+	Cards  []CardDiff    `json:"cards"`
+}
+
+// RollbackResult summarizes the changes made by RollbackGame
+type RollbackResult struct {
+	GameID       uint32 `json:"game_id"`
+	RevisionID   uint32 `json:"revision_id"`
+	GameUpdated  bool   `json:"game_updated"`
+	CardsUpdated int    `json:"cards_updated"`
+	CardsAdded   int    `json:"cards_added"`
+	CardsRemoved int    `json:"cards_removed"`
+}
+
+// gameState is a game and its live cards as of a revision
+type gameState struct {
+	game  model.Game
+	cards map[uint32]model.Card
+}
+
+// ListRevisions returns the revisions of a game and its cards, oldest first.
+// It returns gorm.ErrRecordNotFound when the game does not exist or is not
+// visible to the request.
+func ListRevisions(ctx context.Context, id string) ([]model.Revision, error) {
+	game, err := GetGame(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []model.Revision
+	if err := global.DBEngine.WithContext(ctx).Where("game_id = ?", game.ID).Order("id").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions: %s", err)
+	}
+	return revisions, nil
+}
+
+// DiffRevisions compares the game states as of two revisions of a game
+func DiffRevisions(ctx context.Context, gameID, from, to uint32) (RevisionDiff, error) {
+	if _, err := GetGame(ctx, strconv.FormatUint(uint64(gameID), 10)); err != nil {
+		return RevisionDiff{}, err
+	}
+	db := global.DBEngine.WithContext(ctx)
+	fromState, err := stateAt(db, gameID, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	toState, err := stateAt(db, gameID, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	return RevisionDiff{
+		GameID: gameID,
+		From:   from,
+		To:     to,
+		Game:   gameChanges(fromState.game, toState.game),
+		Cards:  cardDiffs(fromState.cards, toState.cards),
+	}, nil
+}
+
+// cardDiffs lists the cards added, removed or modified between two states,
+// sorted by card ID
+func cardDiffs(from, to map[uint32]model.Card) []CardDiff {
+	diffs := []CardDiff{}
+	for _, id := range cardIDs(from, to) {
+		before, inFrom := from[id]
+		after, inTo := to[id]
+		switch {
+		case !inFrom:
+			diffs = append(diffs, CardDiff{CardID: id, Name: after.Name, Change: CardAdded})
+		case !inTo:
+			diffs = append(diffs, CardDiff{CardID: id, Name: before.Name, Change: CardRemoved})
+		default:
+			if changes := cardChanges(before, after); len(changes) > 0 {
+				diffs = append(diffs, CardDiff{CardID: id, Name: after.Name, Change: CardModified, Fields: changes})
+			}
+		}
+	}
+	return diffs
+}
+
+// RollbackGame restores a game and its cards to their state as of a
+// revision. The restore is written as new changes, so it is recorded as
+// revisions itself and can be rolled back in turn. The game is always left
+// undeleted; cards follow the revision. Only the owner may roll back.
+func RollbackGame(ctx context.Context, gameID, revisionID uint32) (RollbackResult, error) {
+	result := RollbackResult{GameID: gameID, RevisionID: revisionID}
+	actor := app.Actor(ctx)
+	if _, err := GetOwnedGame(ctx, strconv.FormatUint(uint64(gameID), 10)); err != nil {
+		return result, err
+	}
+	tx := global.DBEngine.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	target, err := stateAt(tx, gameID, revisionID)
+	if err != nil {
+		return result, err
+	}
+
+	var game model.Game
+	if err := tx.Where("id = ?", gameID).First(&game).Error; err != nil {
+		return result, err
+	}
+	if target.game.ID != 0 && (len(gameChanges(game, target.game)) > 0 || game.IsDel != 0) {
+		game.Theme, game.CardCount, game.Style, game.Description = target.game.Theme, target.game.CardCount, target.game.Style, target.game.Description
+		game.IsDel, game.DeletedOn, game.ModifiedBy = 0, 0, actor
+		if err := tx.Model(&game).Select("theme", "card_count", "style", "description", "is_del", "deleted_on", "modified_by", "modified_on").Updates(&game).Error; err != nil {
+			return result, fmt.Errorf("failed to restore game: %s", err)
+		}
+		result.GameUpdated = true
+	}
+
+	var cards []model.Card
+	if err := tx.Where("game_id = ?", gameID).Find(&cards).Error; err != nil {
+		return result, fmt.Errorf("failed to fetch cards: %s", err)
+	}
+	for _, card := range cards {
+		want, keep := target.cards[card.ID]
+		switch {
+		case !keep && card.IsDel == 0:
+			card.IsDel, card.DeletedOn, card.ModifiedBy = 1, uint32(time.Now().Unix()), actor
+			if err := tx.Model(&card).Select("is_del", "deleted_on", "modified_by", "modified_on").Updates(&card).Error; err != nil {
+				return result, fmt.Errorf("failed to remove card %d: %s", card.ID, err)
+			}
+			result.CardsRemoved++
+		case keep && (card.IsDel != 0 || len(cardChanges(card, want)) > 0):
+			if card.IsDel != 0 {
+				result.CardsAdded++
+			} else {
+				result.CardsUpdated++
+			}
+			card.Type, card.Name, card.Description, card.Effect = want.Type, want.Name, want.Description, want.Effect
+			card.IsDel, card.DeletedOn, card.ModifiedBy = 0, 0, actor
+			if err := tx.Model(&card).Select("type", "name", "description", "effect", "is_del", "deleted_on", "modified_by", "modified_on").Updates(&card).Error; err != nil {
+				return result, fmt.Errorf("failed to restore card %d: %s", card.ID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return result, fmt.Errorf("failed to commit rollback: %s", err)
+	}
+	return result, nil
+}
+
+// stateAt replays the revisions of a game up to and including revisionID
+func stateAt(db *gorm.DB, gameID, revisionID uint32) (gameState, error) {
+	var count int64
+	if err := db.Model(&model.Revision{}).Where("id = ? AND game_id = ?", revisionID, gameID).Count(&count).Error; err != nil {
+		return gameState{}, fmt.Errorf("failed to fetch revision: %s", err)
+	}
+	if count == 0 {
+		return gameState{}, fmt.Errorf("%w: %d", ErrRevisionNotFound, revisionID)
+	}
+
+	var revisions []model.Revision
+	if err := db.Where("game_id = ? AND id <= ?", gameID, revisionID).Order("id").Find(&revisions).Error; err != nil {
+		return gameState{}, fmt.Errorf("failed to fetch revisions: %s", err)
+	}
+	state := gameState{cards: map[uint32]model.Card{}}
+	for _, rev := range revisions {
+		switch rev.EntityType {
+		case model.RevisionEntityGame:
+			if err := json.Unmarshal(rev.Snapshot, &state.game); err != nil {
+				return gameState{}, fmt.Errorf("invalid snapshot in revision %d: %s", rev.ID, err)
+			}
+		case model.RevisionEntityCard:
+			if rev.Action == model.RevisionActionDelete {
+				delete(state.cards, rev.EntityID)
+				continue
+			}
+			var card model.Card
+			if err := json.Unmarshal(rev.Snapshot, &card); err != nil {
+				return gameState{}, fmt.Errorf("invalid snapshot in revision %d: %s", rev.ID, err)
+			}
+			state.cards[rev.EntityID] = card
+		}
+	}
+	return state, nil
+}
+
+func gameChanges(from, to model.Game) []FieldChange {
+	changes := []FieldChange{}
+	changes = appendChange(changes, "theme", from.Theme, to.Theme)
+	changes = appendChange(changes, "card_count", from.CardCount, to.CardCount)
+	changes = appendChange(changes, "style", from.Style, to.Style)
+	changes = appendChange(changes, "description", from.Description, to.Description)
+	return changes
+}
+
+func cardChanges(from, to model.Card) []FieldChange {
+	var changes []FieldChange
+	changes = appendChange(changes, "type", from.Type, to.Type)
+	changes = appendChange(changes, "name", from.Name, to.Name)
+	changes = appendChange(changes, "description", from.Description, to.Description)
+	changes = appendChange(changes, "effect", from.Effect, to.Effect)
+	return changes
+}
+
+func appendChange[T comparable](changes []FieldChange, field string, from, to T) []FieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, From: from, To: to})
+}
+
+// cardIDs returns the sorted union of the card IDs of two states
+func cardIDs(a, b map[uint32]model.Card) []uint32 {
+	seen := map[uint32]bool{}
+	var ids []uint32
+	for _, m := range []map[uint32]model.Card{a, b} {
+		for id := range m {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
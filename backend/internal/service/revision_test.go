@@ -0,0 +1,113 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"curly-succotash/backend/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCardDiffs(t *testing.T) {
+	from := map[uint32]model.Card{
+		1: {Type: "role", Name: "Knight", Effect: "Strike"},
+		2: {Type: "event", Name: "Storm"},
+		3: {Type: "event", Name: "Feast"},
+	}
+	to := map[uint32]model.Card{
+		1: {Type: "role", Name: "Paladin", Effect: "Smite"},
+		3: {Type: "event", Name: "Feast"},
+		4: {Type: "event", Name: "Flood"},
+	}
+	want := []CardDiff{
+		{CardID: 1, Name: "Paladin", Change: CardModified, Fields: []FieldChange{
+			{Field: "name", From: "Knight", To: "Paladin"},
+			{Field: "effect", From: "Strike", To: "Smite"},
+		}},
+		{CardID: 2, Name: "Storm", Change: CardRemoved},
+		{CardID: 4, Name: "Flood", Change: CardAdded},
+	}
+	if got := cardDiffs(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("cardDiffs() = %+v, want %+v", got, want)
+	}
+	if got := cardDiffs(to, to); got == nil || len(got) != 0 {
+		t.Errorf("cardDiffs() of equal states = %#v, want an empty list", got)
+	}
+}
+
+func TestGameChanges(t *testing.T) {
+	from := model.Game{Theme: "Pirates", CardCount: 10, Style: "classic"}
+	to := model.Game{Theme: "Pirates", CardCount: 12, Style: "noir"}
+	want := []FieldChange{
+		{Field: "card_count", From: 10, To: 12},
+		{Field: "style", From: "classic", To: "noir"},
+	}
+	if got := gameChanges(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("gameChanges() = %+v, want %+v", got, want)
+	}
+	if got := gameChanges(from, from); got == nil || len(got) != 0 {
+		t.Errorf("gameChanges() of equal games = %#v, want an empty list", got)
+	}
+}
+
+func TestStateAt(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Revision{}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	revisions := []model.Revision{
+		{ID: 1, GameID: 7, EntityType: model.RevisionEntityGame, EntityID: 7, Action: model.RevisionActionCreate, Snapshot: snapshot(model.Game{Theme: "Pirates"})},
+		{ID: 2, GameID: 7, EntityType: model.RevisionEntityCard, EntityID: 1, Action: model.RevisionActionCreate, Snapshot: snapshot(model.Card{Name: "Knight"})},
+		{ID: 3, GameID: 8, EntityType: model.RevisionEntityCard, EntityID: 9, Action: model.RevisionActionCreate, Snapshot: snapshot(model.Card{Name: "Other game"})},
+		{ID: 4, GameID: 7, EntityType: model.RevisionEntityCard, EntityID: 2, Action: model.RevisionActionCreate, Snapshot: snapshot(model.Card{Name: "Storm"})},
+		{ID: 5, GameID: 7, EntityType: model.RevisionEntityCard, EntityID: 1, Action: model.RevisionActionUpdate, Snapshot: snapshot(model.Card{Name: "Paladin"})},
+		{ID: 6, GameID: 7, EntityType: model.RevisionEntityCard, EntityID: 2, Action: model.RevisionActionDelete, Snapshot: snapshot(model.Card{Name: "Storm"})},
+	}
+	if err := db.Create(&revisions).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		revision uint32
+		cards    map[uint32]string
+	}{
+		{1, map[uint32]string{}},
+		{4, map[uint32]string{1: "Knight", 2: "Storm"}},
+		{5, map[uint32]string{1: "Paladin", 2: "Storm"}},
+		{6, map[uint32]string{1: "Paladin"}},
+	}
+	for _, tt := range tests {
+		state, err := stateAt(db, 7, tt.revision)
+		if err != nil {
+			t.Fatalf("stateAt(%d): %v", tt.revision, err)
+		}
+		if state.game.Theme != "Pirates" {
+			t.Errorf("stateAt(%d) theme = %q, want %q", tt.revision, state.game.Theme, "Pirates")
+		}
+		cards := map[uint32]string{}
+		for id, card := range state.cards {
+			cards[id] = card.Name
+		}
+		if !reflect.DeepEqual(cards, tt.cards) {
+			t.Errorf("stateAt(%d) cards = %v, want %v", tt.revision, cards, tt.cards)
+		}
+	}
+
+	if _, err := stateAt(db, 7, 3); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("stateAt() of another game's revision error = %v, want %v", err, ErrRevisionNotFound)
+	}
+}
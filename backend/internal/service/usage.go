@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Usage groupings and the columns they group by
+const (
+	UsageByGame  = "game"
+	UsageByUser  = "user"
+	UsageByDay   = "day"
+	UsageByModel = "model"
+)
+
+var usageColumns = map[string]string{
+	UsageByGame:  "game_id",
+	UsageByUser:  "actor",
+	UsageByDay:   "day",
+	UsageByModel: "model",
+}
+
+// UsageFilter selects token usage records. Empty fields match everything;
+// From and To are inclusive UTC dates formatted as YYYY-MM-DD.
+type UsageFilter struct {
+	Actor  string
+	GameID uint32
+	From   string
+	To     string
+}
+
+// UsageTotal sums the AI calls sharing a key: a game ID, username, date or
+// model depending on the grouping
+type UsageTotal struct {
+	Key          string  `json:"key,omitempty"`
+	Calls        int64   `json:"calls"`
+	PromptTokens int64   `json:"prompt_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// UsageReport is the total of the matching records and their subtotals
+type UsageReport struct {
+	Group  string       `json:"group"`
+	Total  UsageTotal   `json:"total"`
+	Groups []UsageTotal `json:"groups"`
+}
+
+// CallCost returns the cost of a call in USD from the AI.Prices table. Calls
+// to models without a price cost nothing and return false.
+func CallCost(call ai.Call) (float64, bool) {
+	price, ok := global.AISetting.Load().Price(call.Model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(call.PromptTokens)*price.Prompt + float64(call.OutputTokens)*price.Output) / 1e6, true
+}
+
+// recordUsage stores the calls made by the request of an audit log entry,
+// against the entry and its target game, and sets the entry's tokens and
+// cost. Costs are computed with the prices in effect when recorded.
+func recordUsage(ctx context.Context, tx *gorm.DB, entry *model.AuditLog, calls []ai.Call) error {
+	var gameID uint32
+	if entry.TargetType == model.AuditTargetGame {
+		id, _ := strconv.ParseUint(entry.TargetID, 10, 32)
+		gameID = uint32(id)
+	}
+	now := time.Unix(int64(entry.CreatedOn), 0).UTC()
+	for _, call := range calls {
+		cost, ok := CallCost(call)
+		if !ok {
+			global.Logger.Warnf(ctx, "AI.Prices has no price for model %s, recording no cost", call.Model)
+		}
+		usage := model.TokenUsage{
+			GameID:       gameID,
+			AuditLogID:   entry.ID,
+			Actor:        entry.Actor,
+			Model:        call.Model,
+			PromptTokens: call.PromptTokens,
+			OutputTokens: call.OutputTokens,
+			Cost:         cost,
+			Day:          now.Format(time.DateOnly),
+			CreatedOn:    entry.CreatedOn,
+		}
+		if err := tx.Create(&usage).Error; err != nil {
+			return fmt.Errorf("failed to record token usage: %s", err)
+		}
+		entry.Cost += cost
+	}
+	return nil
+}
+
+// UsageTotals sums the token usage matching f, grouped by group
+func UsageTotals(ctx context.Context, f UsageFilter, group string) (UsageReport, error) {
+	column, ok := usageColumns[group]
+	if !ok {
+		return UsageReport{}, fmt.Errorf("unknown usage grouping %q", group)
+	}
+	db := global.DBEngine.WithContext(ctx).Model(&model.TokenUsage{})
+	if f.Actor != "" {
+		db = db.Where("actor = ?", f.Actor)
+	}
+	if f.GameID != 0 {
+		db = db.Where("game_id = ?", f.GameID)
+	}
+	if f.From != "" {
+		db = db.Where("day >= ?", f.From)
+	}
+	if f.To != "" {
+		db = db.Where("day <= ?", f.To)
+	}
+
+	report := UsageReport{Group: group, Groups: []UsageTotal{}}
+	err := db.Select(column + " AS `key`, COUNT(*) AS calls, SUM(prompt_tokens) AS prompt_tokens, SUM(output_tokens) AS output_tokens, SUM(cost) AS cost").
+		Group(column).Order(column).Scan(&report.Groups).Error
+	if err != nil {
+		return UsageReport{}, fmt.Errorf("failed to sum token usage: %s", err)
+	}
+	for _, g := range report.Groups {
+		report.Total.Calls += g.Calls
+		report.Total.PromptTokens += g.PromptTokens
+		report.Total.OutputTokens += g.OutputTokens
+		report.Total.Cost += g.Cost
+	}
+	return report, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/logger"
+	"curly-succotash/backend/pkg/setting"
+)
+
+func TestRecordUsage(t *testing.T) {
+	db := useTestDB(t, &model.TokenUsage{})
+	previousAI, previousLogger := global.AISetting.Load(), global.Logger
+	global.AISetting.Store(&setting.AISettingS{Prices: map[string]setting.ModelPrice{
+		"gemini-pro": {Prompt: 1, Output: 4},
+	}})
+	global.Logger = logger.NewLogger(slog.NewTextHandler(io.Discard, nil))
+	t.Cleanup(func() {
+		global.AISetting.Store(previousAI)
+		global.Logger = previousLogger
+	})
+
+	created := time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC)
+	entry := model.AuditLog{ID: 3, Actor: "alice", TargetType: model.AuditTargetGame, TargetID: "7", CreatedOn: uint32(created.Unix())}
+	calls := []ai.Call{
+		{Model: "Gemini-Pro", PromptTokens: 1000, OutputTokens: 500},
+		{Model: "unpriced", PromptTokens: 10, OutputTokens: 20},
+	}
+	if err := recordUsage(context.Background(), db, &entry, calls); err != nil {
+		t.Fatalf("recordUsage: %v", err)
+	}
+	if math.Abs(entry.Cost-0.003) > 1e-12 {
+		t.Errorf("entry cost = %v, want 0.003", entry.Cost)
+	}
+
+	var usages []model.TokenUsage
+	if err := db.Order("id").Find(&usages).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("recorded %d usages, want 2", len(usages))
+	}
+	for _, u := range usages {
+		if u.GameID != 7 || u.AuditLogID != 3 || u.Actor != "alice" || u.Day != "2026-10-19" || u.CreatedOn != entry.CreatedOn {
+			t.Errorf("usage = %+v, want game 7 of audit entry 3 by alice on 2026-10-19", u)
+		}
+	}
+	if usages[1].Cost != 0 {
+		t.Errorf("cost of an unpriced model = %v, want 0", usages[1].Cost)
+	}
+}
+
+func TestUsageTotals(t *testing.T) {
+	db := useTestDB(t, &model.TokenUsage{})
+	usages := []model.TokenUsage{
+		{GameID: 1, Actor: "alice", Model: "pro", PromptTokens: 100, OutputTokens: 10, Cost: 0.5, Day: "2026-10-01"},
+		{GameID: 1, Actor: "alice", Model: "flash", PromptTokens: 200, OutputTokens: 20, Cost: 0.25, Day: "2026-10-02"},
+		{GameID: 2, Actor: "bob", Model: "pro", PromptTokens: 300, OutputTokens: 30, Cost: 1, Day: "2026-10-02"},
+		{GameID: 0, Actor: "bob", Model: "pro", PromptTokens: 400, OutputTokens: 40, Cost: 2, Day: "2026-10-03"},
+	}
+	if err := db.Create(&usages).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter UsageFilter
+		group  string
+		want   UsageReport
+	}{
+		{"by user", UsageFilter{}, UsageByUser, UsageReport{
+			Group: UsageByUser,
+			Total: UsageTotal{Calls: 4, PromptTokens: 1000, OutputTokens: 100, Cost: 3.75},
+			Groups: []UsageTotal{
+				{Key: "alice", Calls: 2, PromptTokens: 300, OutputTokens: 30, Cost: 0.75},
+				{Key: "bob", Calls: 2, PromptTokens: 700, OutputTokens: 70, Cost: 3},
+			},
+		}},
+		{"by game of a user", UsageFilter{Actor: "bob"}, UsageByGame, UsageReport{
+			Group: UsageByGame,
+			Total: UsageTotal{Calls: 2, PromptTokens: 700, OutputTokens: 70, Cost: 3},
+			Groups: []UsageTotal{
+				{Key: "0", Calls: 1, PromptTokens: 400, OutputTokens: 40, Cost: 2},
+				{Key: "2", Calls: 1, PromptTokens: 300, OutputTokens: 30, Cost: 1},
+			},
+		}},
+		{"by model in a date range", UsageFilter{From: "2026-10-02", To: "2026-10-02"}, UsageByModel, UsageReport{
+			Group: UsageByModel,
+			Total: UsageTotal{Calls: 2, PromptTokens: 500, OutputTokens: 50, Cost: 1.25},
+			Groups: []UsageTotal{
+				{Key: "flash", Calls: 1, PromptTokens: 200, OutputTokens: 20, Cost: 0.25},
+				{Key: "pro", Calls: 1, PromptTokens: 300, OutputTokens: 30, Cost: 1},
+			},
+		}},
+		{"by day of a game", UsageFilter{GameID: 1, From: "2026-10-02"}, UsageByDay, UsageReport{
+			Group: UsageByDay,
+			Total: UsageTotal{Calls: 1, PromptTokens: 200, OutputTokens: 20, Cost: 0.25},
+			Groups: []UsageTotal{
+				{Key: "2026-10-02", Calls: 1, PromptTokens: 200, OutputTokens: 20, Cost: 0.25},
+			},
+		}},
+		{"no matches", UsageFilter{Actor: "carol"}, UsageByDay, UsageReport{
+			Group:  UsageByDay,
+			Groups: []UsageTotal{},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UsageTotals(context.Background(), tt.filter, tt.group)
+			if err != nil {
+				t.Fatalf("UsageTotals: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UsageTotals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := UsageTotals(context.Background(), UsageFilter{}, "week"); err == nil {
+		t.Error("UsageTotals() with an unknown grouping succeeded")
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserExists indicates a registration with a taken username
+	ErrUserExists = errors.New("username already taken")
+	// ErrReservedUsername indicates a registration with a name the server
+	// records as an actor itself, such as app.SystemActor
+	ErrReservedUsername = errors.New("username is reserved")
+	// ErrInvalidCredentials indicates an unknown username or a wrong password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidToken indicates a malformed, expired or wrongly typed token
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// TokenPair is the access and refresh token issued on sign in
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	AccessExpiresAt  int64  `json:"access_expires_at"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at"`
+	TokenType        string `json:"token_type"`
+}
+
+// reservedUsername reports whether username names an actor recorded by the
+// server, which would make its user the owner of games created without one
+func reservedUsername(username string) bool {
+	return strings.EqualFold(strings.TrimSpace(username), app.SystemActor)
+}
+
+// Register creates a user with a bcrypt-hashed password
+func Register(ctx context.Context, username, password string) (model.User, error) {
+	username = strings.TrimSpace(username)
+	if reservedUsername(username) {
+		return model.User{}, ErrReservedUsername
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to hash password: %s", err)
+	}
+
+	db := global.DBEngine.WithContext(ctx)
+	var count int64
+	if err := db.Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return model.User{}, fmt.Errorf("failed to check username: %s", err)
+	}
+	if count > 0 {
+		return model.User{}, ErrUserExists
+	}
+
+	user := model.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Model: model.Model{
+			CreatedBy:  username,
+			ModifiedBy: username,
+		},
+	}
+	if err := db.Create(&user).Error; err != nil {
+		return model.User{}, fmt.Errorf("failed to create user: %s", err)
+	}
+	return user, nil
+}
+
+// Login checks a username and password and issues a token pair
+func Login(ctx context.Context, username, password string) (TokenPair, error) {
+	if reservedUsername(username) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	var user model.User
+	err := global.DBEngine.WithContext(ctx).Where("username = ? AND is_del = 0", strings.TrimSpace(username)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("failed to fetch user: %s", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	return issueTokens(user)
+}
+
+// RefreshTokens issues a new token pair for a valid refresh token whose
+// user still exists
+func RefreshTokens(ctx context.Context, refreshToken string) (TokenPair, error) {
+	claims, err := app.ParseToken(refreshToken, app.TokenTypeRefresh)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	var user model.User
+	err = global.DBEngine.WithContext(ctx).Where("id = ? AND is_del = 0", claims.UserID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("failed to fetch user: %s", err)
+	}
+	return issueTokens(user)
+}
+
+func issueTokens(user model.User) (TokenPair, error) {
+	access, accessExpiresAt, err := app.GenerateToken(user.ID, user.Username, app.TokenTypeAccess)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, refreshExpiresAt, err := app.GenerateToken(user.ID, user.Username, app.TokenTypeRefresh)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExpiresAt.Unix(),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExpiresAt.Unix(),
+		TokenType:        "Bearer",
+	}, nil
+}
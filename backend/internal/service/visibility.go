@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"gorm.io/gorm"
+)
+
+// Share link lifetimes
+const (
+	DefaultShareExpire = 7 * 24 * time.Hour
+	MaxShareExpire     = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrNotOwner indicates a change to a game by someone other than its owner
+	ErrNotOwner = errors.New("only the owner can change this game")
+	// ErrInvalidVisibility indicates an unknown visibility value
+	ErrInvalidVisibility = errors.New("invalid visibility")
+)
+
+var visibilities = []string{model.VisibilityPrivate, model.VisibilityUnlisted, model.VisibilityPublic}
+
+// ValidVisibility reports whether v is a known visibility
+func ValidVisibility(v string) bool {
+	return slices.Contains(visibilities, v)
+}
+
+// ListGames returns the public games and the games owned by the request's user
+func ListGames(ctx context.Context) ([]model.Game, error) {
+	var games []model.Game
+	err := global.DBEngine.WithContext(ctx).
+		Where("is_del = 0 AND (visibility = ? OR created_by = ?)", model.VisibilityPublic, app.Actor(ctx)).
+		Find(&games).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch games: %s", err)
+	}
+	return games, nil
+}
+
+// GetGame loads a game that is not deleted and is visible to the request:
+// public and unlisted games, the user's own games, and the game of a share
+// link. Other games return gorm.ErrRecordNotFound so their IDs do not leak.
+func GetGame(ctx context.Context, id string) (model.Game, error) {
+	var game model.Game
+	if err := global.DBEngine.WithContext(ctx).Where("id = ? AND is_del = 0", id).First(&game).Error; err != nil {
+		return game, err
+	}
+	if !canView(ctx, game) {
+		return model.Game{}, gorm.ErrRecordNotFound
+	}
+	return game, nil
+}
+
+// GetOwnedGame loads a game visible to the request, as GetGame does, and
+// returns ErrNotOwner unless the request's user owns it
+func GetOwnedGame(ctx context.Context, id string) (model.Game, error) {
+	game, err := GetGame(ctx, id)
+	if err != nil {
+		return game, err
+	}
+	if app.SharedGame(ctx) != 0 || game.CreatedBy != app.Actor(ctx) {
+		return model.Game{}, ErrNotOwner
+	}
+	return game, nil
+}
+
+// SetVisibility changes the visibility of a game owned by the request's user
+func SetVisibility(ctx context.Context, id, visibility string) (model.Game, error) {
+	if !ValidVisibility(visibility) {
+		return model.Game{}, fmt.Errorf("%w: %q", ErrInvalidVisibility, visibility)
+	}
+	game, err := GetOwnedGame(ctx, id)
+	if err != nil {
+		return game, err
+	}
+	game.Visibility = visibility
+	game.ModifiedBy = app.Actor(ctx)
+	if err := global.DBEngine.WithContext(ctx).Model(&game).Select("visibility", "modified_by", "modified_on").Updates(&game).Error; err != nil {
+		return game, fmt.Errorf("failed to update visibility: %s", err)
+	}
+	return game, nil
+}
+
+// CreateShareLink signs a read-only share token for a game owned by the
+// request's user. The token works whatever the game's visibility.
+func CreateShareLink(ctx context.Context, id string, expire time.Duration) (string, time.Time, error) {
+	game, err := GetOwnedGame(ctx, id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return app.GenerateShareToken(game.ID, expire)
+}
+
+func canView(ctx context.Context, game model.Game) bool {
+	switch {
+	case game.Visibility == model.VisibilityPublic, game.Visibility == model.VisibilityUnlisted:
+		return true
+	case app.SharedGame(ctx) != 0:
+		return app.SharedGame(ctx) == game.ID
+	default:
+		return game.CreatedBy == app.Actor(ctx)
+	}
+}
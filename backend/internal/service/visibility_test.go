@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/pkg/app"
+
+	"gorm.io/gorm"
+)
+
+func TestCanView(t *testing.T) {
+	alice := app.WithActor(context.Background(), "alice")
+	bob := app.WithActor(context.Background(), "bob")
+	anonymous := context.Background()
+	shared := app.WithSharedGame(anonymous, 7)
+	aliceShared := app.WithSharedGame(alice, 8)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		visibility string
+		want       bool
+	}{
+		{"owner of a private game", alice, model.VisibilityPrivate, true},
+		{"other user of a private game", bob, model.VisibilityPrivate, false},
+		{"anonymous of a private game", anonymous, model.VisibilityPrivate, false},
+		{"share link of a private game", shared, model.VisibilityPrivate, true},
+		{"owner with a share link of another game", aliceShared, model.VisibilityPrivate, false},
+		{"other user of an unlisted game", bob, model.VisibilityUnlisted, true},
+		{"other user of a public game", bob, model.VisibilityPublic, true},
+		{"share link of another public game", aliceShared, model.VisibilityPublic, true},
+		{"other user of an unknown visibility", bob, "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := model.Game{Visibility: tt.visibility}
+			game.ID = 7
+			game.CreatedBy = "alice"
+			if got := canView(tt.ctx, game); got != tt.want {
+				t.Errorf("canView() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOwnedGame(t *testing.T) {
+	db := useTestDB(t, &model.Game{})
+	games := []model.Game{
+		{Visibility: model.VisibilityPrivate, Theme: "Private"},
+		{Visibility: model.VisibilityPublic, Theme: "Public"},
+		{Visibility: model.VisibilityPublic, Theme: "Deleted"},
+	}
+	for i := range games {
+		games[i].CreatedBy = "alice"
+	}
+	games[2].IsDel = 1
+	if err := db.Create(&games).Error; err != nil {
+		t.Fatal(err)
+	}
+	alice := app.WithActor(context.Background(), "alice")
+	bob := app.WithActor(context.Background(), "bob")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      string
+		wantErr error
+	}{
+		{"owner", alice, "1", nil},
+		{"other user of a private game", bob, "1", gorm.ErrRecordNotFound},
+		{"other user of a public game", bob, "2", ErrNotOwner},
+		{"share link", app.WithSharedGame(alice, 1), "1", ErrNotOwner},
+		{"deleted", alice, "3", gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetOwnedGame(tt.ctx, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetOwnedGame(%s) = %v, want %v", tt.id, err, tt.wantErr)
+			}
+		})
+	}
+
+	listed, err := ListGames(bob)
+	if err != nil {
+		t.Fatalf("ListGames: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Theme != "Public" {
+		t.Errorf("ListGames() for another user = %+v, want only the public game", listed)
+	}
+}
@@ -0,0 +1,250 @@
+package theme
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"curly-succotash/backend/global"
+)
+
+// DefaultName is the theme used when an export request does not select one
+const DefaultName = "default"
+
+const (
+	descriptorFile = "theme.json"
+	htmlFile       = "card.html"
+)
+
+// ErrNotFound indicates the requested theme does not exist
+var ErrNotFound = errors.New("theme not found")
+
+//go:embed builtin
+var builtin embed.FS
+
+// TypeStyle defines how cards of one type are decorated
+type TypeStyle struct {
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// Layout describes the card grid used by the native (gofpdf) renderer, in millimetres
+type Layout struct {
+	CardWidth   float64 `json:"card_width"`
+	CardHeight  float64 `json:"card_height"`
+	CardsPerRow int     `json:"cards_per_row"`
+	CardsPerCol int     `json:"cards_per_col"`
+	Margin      float64 `json:"margin"`
+	Gap         float64 `json:"gap"`
+}
+
+// validate rejects layouts the renderers cannot divide a page by
+func (l Layout) validate() error {
+	if l.CardsPerRow <= 0 || l.CardsPerCol <= 0 {
+		return fmt.Errorf("cards_per_row and cards_per_col must be positive, got %d and %d", l.CardsPerRow, l.CardsPerCol)
+	}
+	if l.CardWidth <= 0 || l.CardHeight <= 0 {
+		return fmt.Errorf("card_width and card_height must be positive, got %v and %v", l.CardWidth, l.CardHeight)
+	}
+	if l.Margin <= 0 {
+		return fmt.Errorf("margin must be positive, got %v", l.Margin)
+	}
+	if l.Gap < 0 {
+		return fmt.Errorf("gap must not be negative, got %v", l.Gap)
+	}
+	return nil
+}
+
+// Theme is a card design made of a layout descriptor and an HTML template
+type Theme struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Background  string               `json:"background"`
+	Border      string               `json:"border"`
+	Text        string               `json:"text"`
+	Layout      Layout               `json:"layout"`
+	Types       map[string]TypeStyle `json:"types"`
+
+	html        *template.Template
+	fingerprint string
+}
+
+// Style returns the decoration for a card type, or a neutral style for
+// types the theme does not define
+func (t *Theme) Style(cardType string) TypeStyle {
+	if s, ok := t.Types[cardType]; ok {
+		return s
+	}
+	return TypeStyle{Color: t.Border}
+}
+
+// HTML returns the template rendering a whole game for the HTML renderer
+func (t *Theme) HTML() *template.Template {
+	return t.html
+}
+
+// Fingerprint identifies the exact descriptor and template content of the
+// theme, so cached exports are discarded when a theme file is edited
+func (t *Theme) Fingerprint() string {
+	return t.fingerprint
+}
+
+// List returns all available themes sorted by name. Themes in the theme
+// folder override built-in themes with the same name.
+func List() ([]*Theme, error) {
+	themes := map[string]*Theme{}
+	builtinFS, _ := fs.Sub(builtin, "builtin")
+	if err := loadAll(builtinFS, themes); err != nil {
+		return nil, err
+	}
+	if dir := folder(); dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			if err := loadAll(os.DirFS(dir), themes); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	list := make([]*Theme, 0, len(themes))
+	for _, t := range themes {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Load returns the theme with the given name, or the default theme when name is empty
+func Load(name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultName
+	}
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return nil, ErrNotFound
+	}
+	if dir := folder(); dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, name, descriptorFile)); err == nil {
+			return load(os.DirFS(dir), name)
+		}
+	}
+	builtinFS, _ := fs.Sub(builtin, "builtin")
+	if _, err := fs.Stat(builtinFS, name+"/"+descriptorFile); err != nil {
+		return nil, ErrNotFound
+	}
+	return load(builtinFS, name)
+}
+
+func folder() string {
+	storage := global.StoragePathSetting.Load()
+	if storage == nil {
+		return ""
+	}
+	return storage.ThemeFolder
+}
+
+func loadAll(fsys fs.FS, themes map[string]*Theme) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read theme folder: %s", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(fsys, e.Name()+"/"+descriptorFile); err != nil {
+			continue
+		}
+		t, err := load(fsys, e.Name())
+		if err != nil {
+			return err
+		}
+		themes[t.Name] = t
+	}
+	return nil
+}
+
+func load(fsys fs.FS, name string) (*Theme, error) {
+	raw, err := fs.ReadFile(fsys, name+"/"+descriptorFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme %s: %s", name, err)
+	}
+	t := defaults()
+	if err := json.Unmarshal(raw, t); err != nil {
+		return nil, fmt.Errorf("failed to parse theme %s: %s", name, err)
+	}
+	// The directory name is authoritative so that lookups by name always match
+	t.Name = name
+	if err := t.Layout.validate(); err != nil {
+		return nil, fmt.Errorf("invalid layout of theme %s: %s", name, err)
+	}
+
+	src, err := fs.ReadFile(fsys, name+"/"+htmlFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template of theme %s: %s", name, err)
+	}
+	t.html, err = template.New(name).Funcs(t.funcs()).Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template of theme %s: %s", name, err)
+	}
+	sum := sha256.New()
+	sum.Write(raw)
+	sum.Write(src)
+	t.fingerprint = hex.EncodeToString(sum.Sum(nil))
+	return t, nil
+}
+
+// defaults returns a theme pre-filled with the values used before themes
+// existed, so descriptors only need to specify what they change
+func defaults() *Theme {
+	return &Theme{
+		Background: "#ffffff",
+		Border:     "#000000",
+		Text:       "#000000",
+		Layout: Layout{
+			CardWidth:   63.5, // standard poker card
+			CardHeight:  88.9,
+			CardsPerRow: 3,
+			CardsPerCol: 2,
+			Margin:      10,
+			Gap:         5,
+		},
+		Types: map[string]TypeStyle{},
+	}
+}
+
+func (t *Theme) funcs() template.FuncMap {
+	return template.FuncMap{
+		"mod": func(i, n int) int { return i % n },
+		"title": func(s string) string {
+			if len(s) == 0 {
+				return s
+			}
+			return strings.ToUpper(s[:1]) + s[1:]
+		},
+		"typeColor": func(cardType string) string { return t.Style(cardType).Color },
+		"typeIcon":  func(cardType string) string { return t.Style(cardType).Icon },
+	}
+}
+
+// RGB converts a #rrggbb color to its components, returning black for
+// malformed values
+func RGB(color string) (r, g, b int) {
+	color = strings.TrimPrefix(color, "#")
+	if len(color) != 6 {
+		return 0, 0, 0
+	}
+	v, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+}
@@ -0,0 +1,100 @@
+package theme
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLayoutValidate(t *testing.T) {
+	valid := defaults().Layout
+	tests := []struct {
+		name    string
+		edit    func(l *Layout)
+		wantErr string
+	}{
+		{"defaults", func(l *Layout) {}, ""},
+		{"no gap", func(l *Layout) { l.Gap = 0 }, ""},
+		{"no columns", func(l *Layout) { l.CardsPerRow = 0 }, "cards_per_row"},
+		{"negative rows", func(l *Layout) { l.CardsPerCol = -1 }, "cards_per_col"},
+		{"no width", func(l *Layout) { l.CardWidth = 0 }, "card_width"},
+		{"negative height", func(l *Layout) { l.CardHeight = -88.9 }, "card_height"},
+		{"no margin", func(l *Layout) { l.Margin = 0 }, "margin"},
+		{"negative gap", func(l *Layout) { l.Gap = -1 }, "gap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := valid
+			tt.edit(&l)
+			err := l.validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validate() = %v, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validate() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	const html = "<html>{{range .Cards}}{{title .Name}}{{end}}</html>"
+	fsys := fstest.MapFS{
+		"partial/theme.json":     {Data: []byte(`{"name": "other", "background": "#112233", "layout": {"cards_per_row": 4}}`)},
+		"partial/card.html":      {Data: []byte(html)},
+		"zero/theme.json":        {Data: []byte(`{"layout": {"margin": 0}}`)},
+		"zero/card.html":         {Data: []byte(html)},
+		"broken/theme.json":      {Data: []byte(`{"layout": `)},
+		"broken/card.html":       {Data: []byte(html)},
+		"template/theme.json":    {Data: []byte(`{}`)},
+		"template/card.html":     {Data: []byte("{{range}}")},
+		"no-template/theme.json": {Data: []byte(`{}`)},
+	}
+
+	theme, err := load(fsys, "partial")
+	if err != nil {
+		t.Fatalf("load(partial): %v", err)
+	}
+	want := defaults().Layout
+	want.CardsPerRow = 4
+	if theme.Name != "partial" || theme.Background != "#112233" || theme.Border != "#000000" || theme.Layout != want {
+		t.Errorf("load(partial) = %+v, want the named directory with defaults for unset values", theme)
+	}
+	if theme.HTML() == nil || theme.Fingerprint() == "" {
+		t.Error("load(partial) has no template or fingerprint")
+	}
+
+	for _, name := range []string{"zero", "broken", "template", "no-template"} {
+		if _, err := load(fsys, name); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("load(%s) = %v, want an error naming the theme", name, err)
+		}
+	}
+
+	edited := fstest.MapFS{
+		"partial/theme.json": fsys["partial/theme.json"],
+		"partial/card.html":  {Data: []byte(html + "\n")},
+	}
+	other, err := load(edited, "partial")
+	if err != nil {
+		t.Fatalf("load(partial) after an edit: %v", err)
+	}
+	if other.Fingerprint() == theme.Fingerprint() {
+		t.Error("editing the template kept the fingerprint")
+	}
+}
+
+func TestLoadBuiltin(t *testing.T) {
+	theme, err := Load("")
+	if err != nil {
+		t.Fatalf("Load(\"\"): %v", err)
+	}
+	if theme.Name != DefaultName {
+		t.Errorf("Load(\"\") name = %q, want %q", theme.Name, DefaultName)
+	}
+	for _, name := range []string{"missing", "../default", "default/theme.json"} {
+		if _, err := Load(name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q) = %v, want %v", name, err, ErrNotFound)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package vtt
+
+import (
+	"strconv"
+	"strings"
+
+	"curly-succotash/backend/internal/model"
+)
+
+// Tabletop Simulator imports custom decks from sheets of 10 x 7 cards
+const (
+	SheetColumns = 10
+	SheetRows    = 7
+	SheetSize    = SheetColumns * SheetRows
+)
+
+// TTSSave is a Tabletop Simulator saved object, loadable from the
+// "Saved Objects" menu
+type TTSSave struct {
+	SaveName     string      `json:"SaveName"`
+	ObjectStates []TTSObject `json:"ObjectStates"`
+}
+
+// TTSTransform positions an object on the table
+type TTSTransform struct {
+	PosX   float64 `json:"posX"`
+	PosY   float64 `json:"posY"`
+	PosZ   float64 `json:"posZ"`
+	RotX   float64 `json:"rotX"`
+	RotY   float64 `json:"rotY"`
+	RotZ   float64 `json:"rotZ"`
+	ScaleX float64 `json:"scaleX"`
+	ScaleY float64 `json:"scaleY"`
+	ScaleZ float64 `json:"scaleZ"`
+}
+
+// TTSCustomDeck references a card sheet image and its back
+type TTSCustomDeck struct {
+	FaceURL      string `json:"FaceURL"`
+	BackURL      string `json:"BackURL"`
+	NumWidth     int    `json:"NumWidth"`
+	NumHeight    int    `json:"NumHeight"`
+	BackIsHidden bool   `json:"BackIsHidden"`
+	UniqueBack   bool   `json:"UniqueBack"`
+	Type         int    `json:"Type"`
+}
+
+// TTSObject is either the deck or one of its cards
+type TTSObject struct {
+	Name             string                   `json:"Name"`
+	Transform        TTSTransform             `json:"Transform"`
+	Nickname         string                   `json:"Nickname"`
+	Description      string                   `json:"Description"`
+	CardID           int                      `json:"CardID,omitempty"`
+	DeckIDs          []int                    `json:"DeckIDs,omitempty"`
+	CustomDeck       map[string]TTSCustomDeck `json:"CustomDeck"`
+	ContainedObjects []TTSObject              `json:"ContainedObjects,omitempty"`
+}
+
+// faceDown places the deck on the table with the card backs up
+var faceDown = TTSTransform{PosY: 1, RotY: 180, RotZ: 180, ScaleX: 1, ScaleY: 1, ScaleZ: 1}
+
+// BuildTTS creates a saved object containing the game's cards as a single
+// deck. sheetURLs holds one image per SheetSize cards, in card order.
+func BuildTTS(game model.Game, cards []model.Card, sheetURLs []string, backURL string) TTSSave {
+	decks := make(map[string]TTSCustomDeck, len(sheetURLs))
+	for i, url := range sheetURLs {
+		decks[strconv.Itoa(i+1)] = TTSCustomDeck{
+			FaceURL:      url,
+			BackURL:      backURL,
+			NumWidth:     SheetColumns,
+			NumHeight:    SheetRows,
+			BackIsHidden: true,
+		}
+	}
+
+	deck := TTSObject{
+		Name:        "DeckCustom",
+		Transform:   faceDown,
+		Nickname:    game.Theme,
+		Description: game.Description,
+		CustomDeck:  decks,
+	}
+	for i, card := range cards {
+		// CardIDs are the deck key times 100 plus the slot on the sheet
+		sheet := i/SheetSize + 1
+		id := sheet*100 + i%SheetSize
+		key := strconv.Itoa(sheet)
+		deck.DeckIDs = append(deck.DeckIDs, id)
+		deck.ContainedObjects = append(deck.ContainedObjects, TTSObject{
+			Name:        "Card",
+			Transform:   faceDown,
+			Nickname:    card.Name,
+			Description: cardText(card),
+			CardID:      id,
+			CustomDeck:  map[string]TTSCustomDeck{key: decks[key]},
+		})
+	}
+
+	return TTSSave{SaveName: game.Theme, ObjectStates: []TTSObject{deck}}
+}
+
+// cardText combines the card fields shown in a tabletop tooltip
+func cardText(card model.Card) string {
+	var parts []string
+	if card.Type != "" {
+		parts = append(parts, "["+card.Type+"]")
+	}
+	if card.Description != "" {
+		parts = append(parts, card.Description)
+	}
+	if card.Effect != "" {
+		parts = append(parts, "Effect: "+card.Effect)
+	}
+	return strings.Join(parts, "\n")
+}
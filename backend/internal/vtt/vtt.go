@@ -0,0 +1,75 @@
+package vtt
+
+import (
+	"curly-succotash/backend/internal/model"
+)
+
+// Format and version identify the generic virtual tabletop export
+const (
+	Format  = "curly-succotash-vtt"
+	Version = 1
+)
+
+// Deck is a tool-neutral description of a game's cards, meant to be
+// converted by scripts for tabletops without a dedicated exporter
+type Deck struct {
+	Format    string   `json:"format"`
+	Version   int      `json:"version"`
+	Game      DeckGame `json:"game"`
+	CardSize  CardSize `json:"card_size"`
+	BackImage string   `json:"back_image"`
+	Cards     []Card   `json:"cards"`
+}
+
+// DeckGame holds the game information of a deck
+type DeckGame struct {
+	ID          uint32 `json:"id"`
+	Theme       string `json:"theme"`
+	Style       string `json:"style"`
+	Description string `json:"description"`
+}
+
+// CardSize is the physical card size in millimetres
+type CardSize struct {
+	WidthMM  float64 `json:"width_mm"`
+	HeightMM float64 `json:"height_mm"`
+}
+
+// Card is a single card of a deck with the URL of its face image
+type Card struct {
+	ID          uint32 `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Effect      string `json:"effect"`
+	Image       string `json:"image"`
+}
+
+// BuildDeck creates the generic export. imageURL returns the face image
+// URL of a card.
+func BuildDeck(game model.Game, cards []model.Card, size CardSize, backURL string, imageURL func(model.Card) string) Deck {
+	deck := Deck{
+		Format:  Format,
+		Version: Version,
+		Game: DeckGame{
+			ID:          game.ID,
+			Theme:       game.Theme,
+			Style:       game.Style,
+			Description: game.Description,
+		},
+		CardSize:  size,
+		BackImage: backURL,
+		Cards:     make([]Card, 0, len(cards)),
+	}
+	for _, card := range cards {
+		deck.Cards = append(deck.Cards, Card{
+			ID:          card.ID,
+			Type:        card.Type,
+			Name:        card.Name,
+			Description: card.Description,
+			Effect:      card.Effect,
+			Image:       imageURL(card),
+		})
+	}
+	return deck
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Game20261019AddGameLineage adds the ParentID field
+type Game20261019AddGameLineage struct {
+	Model20250520AddGameInfo
+	ParentID    uint32    `gorm:"not null;default:0;index" json:"parent_id"` // Added: forked from, 0 for originals
+	Theme       string    `gorm:"type:text;not null" json:"theme"`
+	CardCount   int       `gorm:"column:card_count;not null" json:"card_count"`
+	Style       string    `gorm:"type:text;not null" json:"style"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"type:datetime;not null" json:"created_at"`
+}
+
+// TableName specifies the table name for Game20261019AddGameLineage
+func (Game20261019AddGameLineage) TableName() string {
+	return "games"
+}
+
+var AddGameLineage = &gormigrate.Migration{
+	ID: "20261019120000_add_game_lineage",
+	Migrate: func(tx *gorm.DB) error {
+		// Add parent_id column
+		return tx.Migrator().AutoMigrate(&Game20261019AddGameLineage{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop parent_id column
+		return tx.Migrator().DropColumn(&Game20261019AddGameLineage{}, "parent_id")
+	},
+}
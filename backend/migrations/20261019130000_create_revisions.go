@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Revision20261019 records a snapshot of a game or card after a change
+type Revision20261019 struct {
+	ID         uint32          `gorm:"primaryKey" json:"id"`
+	GameID     uint32          `gorm:"not null;index" json:"game_id"`
+	EntityType string          `gorm:"type:varchar(16);not null" json:"entity_type"` // game, card
+	EntityID   uint32          `gorm:"not null" json:"entity_id"`
+	Action     string          `gorm:"type:varchar(16);not null" json:"action"` // create, update, delete
+	Snapshot   json.RawMessage `gorm:"type:text;not null" json:"snapshot"`
+	CreatedBy  string          `json:"created_by"`
+	CreatedOn  uint32          `json:"created_on"`
+}
+
+// TableName specifies the table name for Revision20261019
+func (Revision20261019) TableName() string {
+	return "revisions"
+}
+
+var CreateRevisions = &gormigrate.Migration{
+	ID: "20261019130000_create_revisions",
+	Migrate: func(tx *gorm.DB) error {
+		// Create revisions table
+		if err := tx.Migrator().AutoMigrate(&Revision20261019{}); err != nil {
+			return err
+		}
+
+		// Record the current state of existing games and cards as their first
+		// revision so diffs and rollbacks have a baseline
+		now := uint32(time.Now().Unix())
+		var games []Game20261019AddGameLineage
+		if err := tx.Find(&games).Error; err != nil {
+			return err
+		}
+		for _, g := range games {
+			if err := createBaselineRevision(tx, g.ID, "game", g.ID, g.IsDel, g.CreatedBy, now, g); err != nil {
+				return err
+			}
+		}
+		var cards []Card20250520AddGameInfo
+		if err := tx.Find(&cards).Error; err != nil {
+			return err
+		}
+		for _, c := range cards {
+			if err := createBaselineRevision(tx, uint32(c.GameID), "card", c.ID, c.IsDel, c.CreatedBy, now, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop revisions table
+		return tx.Migrator().DropTable("revisions")
+	},
+}
+
+func createBaselineRevision(tx *gorm.DB, gameID uint32, entityType string, entityID uint32, isDel uint8, createdBy string, now uint32, value any) error {
+	snapshot, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	action := "create"
+	if isDel != 0 {
+		action = "delete"
+	}
+	return tx.Create(&Revision20261019{
+		GameID:     gameID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Snapshot:   snapshot,
+		CreatedBy:  createdBy,
+		CreatedOn:  now,
+	}).Error
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// User20261019 represents a user account for this migration
+type User20261019 struct {
+	Model20250520AddGameInfo
+	Username     string `gorm:"type:varchar(64);not null;uniqueIndex" json:"username"`
+	PasswordHash string `gorm:"type:varchar(255);not null" json:"-"`
+}
+
+// TableName specifies the table name for User20261019
+func (User20261019) TableName() string {
+	return "users"
+}
+
+var CreateUsers = &gormigrate.Migration{
+	ID: "20261019140000_create_users",
+	Migrate: func(tx *gorm.DB) error {
+		// Create users table
+		return tx.Migrator().AutoMigrate(&User20261019{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop users table
+		return tx.Migrator().DropTable("users")
+	},
+}
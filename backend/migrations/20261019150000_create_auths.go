@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Auth20261019 represents an AppKey/AppSecret credential for this migration
+type Auth20261019 struct {
+	Model20250520AddGameInfo
+	Name       string `gorm:"type:varchar(128);not null" json:"name"`
+	AppKey     string `gorm:"type:varchar(64);not null;uniqueIndex" json:"app_key"`
+	SecretHash string `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes     string `gorm:"type:varchar(255);not null" json:"scopes"`
+	RotatedOn  uint32 `gorm:"not null;default:0" json:"rotated_on"`
+}
+
+// TableName specifies the table name for Auth20261019
+func (Auth20261019) TableName() string {
+	return "auths"
+}
+
+var CreateAuths = &gormigrate.Migration{
+	ID: "20261019150000_create_auths",
+	Migrate: func(tx *gorm.DB) error {
+		// Create auths table
+		return tx.Migrator().AutoMigrate(&Auth20261019{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop auths table
+		return tx.Migrator().DropTable("auths")
+	},
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Game20261019AddGameVisibility adds the Visibility field
+type Game20261019AddGameVisibility struct {
+	Model20250520AddGameInfo
+	ParentID    uint32    `gorm:"not null;default:0;index" json:"parent_id"`
+	Visibility  string    `gorm:"type:varchar(16);not null;default:private;index" json:"visibility"` // Added: private, unlisted, public
+	Theme       string    `gorm:"type:text;not null" json:"theme"`
+	CardCount   int       `gorm:"column:card_count;not null" json:"card_count"`
+	Style       string    `gorm:"type:text;not null" json:"style"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"type:datetime;not null" json:"created_at"`
+}
+
+// TableName specifies the table name for Game20261019AddGameVisibility
+func (Game20261019AddGameVisibility) TableName() string {
+	return "games"
+}
+
+var AddGameVisibility = &gormigrate.Migration{
+	ID: "20261019160000_add_game_visibility",
+	Migrate: func(tx *gorm.DB) error {
+		// Add visibility column
+		if err := tx.Migrator().AutoMigrate(&Game20261019AddGameVisibility{}); err != nil {
+			return err
+		}
+		// Games created before ownership existed were visible to everyone
+		return tx.Exec("UPDATE games SET visibility = ?", "public").Error
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop visibility column
+		return tx.Migrator().DropColumn(&Game20261019AddGameVisibility{}, "visibility")
+	},
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// RateLimit20261019 represents a shared rate limiter bucket for this migration
+type RateLimit20261019 struct {
+	Key       string `gorm:"column:bucket_key;type:varchar(191);primaryKey"`
+	Tokens    int64  `gorm:"not null"`
+	Tick      int64  `gorm:"not null"`
+	UpdatedOn int64  `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for RateLimit20261019
+func (RateLimit20261019) TableName() string {
+	return "rate_limits"
+}
+
+var CreateRateLimits = &gormigrate.Migration{
+	ID: "20261019170000_create_rate_limits",
+	Migrate: func(tx *gorm.DB) error {
+		// Create rate_limits table
+		return tx.Migrator().AutoMigrate(&RateLimit20261019{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop rate_limits table
+		return tx.Migrator().DropTable("rate_limits")
+	},
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AuditLog20261019 records an audited request for this migration
+type AuditLog20261019 struct {
+	ID           uint32 `gorm:"primaryKey"`
+	Action       string `gorm:"type:varchar(32);not null;index"`
+	Actor        string `gorm:"type:varchar(64);index"`
+	IP           string `gorm:"type:varchar(64)"`
+	Route        string `gorm:"type:varchar(128)"`
+	TargetType   string `gorm:"type:varchar(16);index:idx_audit_logs_target"`
+	TargetID     string `gorm:"type:varchar(64);index:idx_audit_logs_target"`
+	Params       string `gorm:"type:text"`
+	Status       int    `gorm:"not null"`
+	Outcome      string `gorm:"type:varchar(16);not null"`
+	PromptTokens int64  `gorm:"not null;default:0"`
+	OutputTokens int64  `gorm:"not null;default:0"`
+	CreatedOn    uint32 `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for AuditLog20261019
+func (AuditLog20261019) TableName() string {
+	return "audit_logs"
+}
+
+var CreateAuditLogs = &gormigrate.Migration{
+	ID: "20261019180000_create_audit_logs",
+	Migrate: func(tx *gorm.DB) error {
+		// Create audit_logs table
+		return tx.Migrator().AutoMigrate(&AuditLog20261019{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop audit_logs table
+		return tx.Migrator().DropTable("audit_logs")
+	},
+}
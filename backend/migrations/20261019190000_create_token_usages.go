@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// TokenUsage20261019 records the tokens and cost of an AI call for this migration
+type TokenUsage20261019 struct {
+	ID           uint32  `gorm:"primaryKey"`
+	GameID       uint32  `gorm:"not null;default:0;index"`
+	AuditLogID   uint32  `gorm:"not null;default:0;index"`
+	Actor        string  `gorm:"type:varchar(64);index"`
+	Model        string  `gorm:"type:varchar(64);not null"`
+	PromptTokens int64   `gorm:"not null;default:0"`
+	OutputTokens int64   `gorm:"not null;default:0"`
+	Cost         float64 `gorm:"not null;default:0"`
+	Day          string  `gorm:"type:varchar(10);not null;index"`
+	CreatedOn    uint32  `gorm:"not null"`
+}
+
+// TableName specifies the table name for TokenUsage20261019
+func (TokenUsage20261019) TableName() string {
+	return "token_usages"
+}
+
+// AuditLog20261019AddCost adds the Cost field
+type AuditLog20261019AddCost struct {
+	AuditLog20261019
+	Cost float64 `gorm:"not null;default:0"` // Added
+}
+
+// TableName specifies the table name for AuditLog20261019AddCost
+func (AuditLog20261019AddCost) TableName() string {
+	return "audit_logs"
+}
+
+var CreateTokenUsages = &gormigrate.Migration{
+	ID: "20261019190000_create_token_usages",
+	Migrate: func(tx *gorm.DB) error {
+		// Create token_usages table
+		if err := tx.Migrator().AutoMigrate(&TokenUsage20261019{}); err != nil {
+			return err
+		}
+		// Add cost column to audit_logs
+		return tx.Migrator().AutoMigrate(&AuditLog20261019AddCost{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop cost column and token_usages table
+		if err := tx.Migrator().DropColumn(&AuditLog20261019AddCost{}, "cost"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable("token_usages")
+	},
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AuditLog20261019AddRemoteIP adds the RemoteIP field
+type AuditLog20261019AddRemoteIP struct {
+	AuditLog20261019AddCost
+	RemoteIP string `gorm:"type:varchar(64)"` // Added
+}
+
+// TableName specifies the table name for AuditLog20261019AddRemoteIP
+func (AuditLog20261019AddRemoteIP) TableName() string {
+	return "audit_logs"
+}
+
+var AddAuditRemoteIP = &gormigrate.Migration{
+	ID: "20261019200000_add_audit_remote_ip",
+	Migrate: func(tx *gorm.DB) error {
+		// Add remote_ip column
+		return tx.Migrator().AutoMigrate(&AuditLog20261019AddRemoteIP{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop remote_ip column
+		return tx.Migrator().DropColumn(&AuditLog20261019AddRemoteIP{}, "remote_ip")
+	},
+}
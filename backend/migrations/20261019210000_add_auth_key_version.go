@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Auth20261019AddKeyVersion adds the KeyVersion field
+type Auth20261019AddKeyVersion struct {
+	Auth20261019
+	KeyVersion uint32 `gorm:"not null;default:0" json:"key_version"` // Added
+}
+
+// TableName specifies the table name for Auth20261019AddKeyVersion
+func (Auth20261019AddKeyVersion) TableName() string {
+	return "auths"
+}
+
+var AddAuthKeyVersion = &gormigrate.Migration{
+	ID: "20261019210000_add_auth_key_version",
+	Migrate: func(tx *gorm.DB) error {
+		// Add key_version column
+		return tx.Migrator().AutoMigrate(&Auth20261019AddKeyVersion{})
+	},
+	Rollback: func(tx *gorm.DB) error {
+		// Drop key_version column
+		return tx.Migrator().DropColumn(&Auth20261019AddKeyVersion{}, "key_version")
+	},
+}
@@ -6,6 +6,16 @@ func GetMigrations() []*gormigrate.Migration {
 	return []*gormigrate.Migration{
 		createTables,
 		AddGameInfo,
+		AddGameLineage,
+		CreateRevisions,
+		CreateUsers,
+		CreateAuths,
+		AddGameVisibility,
+		CreateRateLimits,
+		CreateAuditLogs,
+		CreateTokenUsages,
+		AddAuditRemoteIP,
+		AddAuthKeyVersion,
 		// NOTE: Add future migrations here
 	}
 }
@@ -0,0 +1,35 @@
+package app
+
+import "context"
+
+// SystemActor is recorded as CreatedBy/ModifiedBy when no user is signed in
+const SystemActor = "system"
+
+type (
+	actorKey      struct{}
+	sharedGameKey struct{}
+)
+
+// WithActor returns a context carrying the name of the user making the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the user set by WithActor, or SystemActor
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithSharedGame returns a context granting read access to a game through a share link
+func WithSharedGame(ctx context.Context, gameID uint32) context.Context {
+	return context.WithValue(ctx, sharedGameKey{}, gameID)
+}
+
+// SharedGame returns the game set by WithSharedGame, or 0
+func SharedGame(ctx context.Context) uint32 {
+	id, _ := ctx.Value(sharedGameKey{}).(uint32)
+	return id
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response writes API responses for a request
+type Response struct {
+	Ctx *gin.Context
+}
+
+// ErrorResponse is the body of every failed API request
+type ErrorResponse struct {
+	Code    int      `json:"code"`
+	Msg     string   `json:"msg"`
+	Details []string `json:"details,omitempty"`
+	TraceID string   `json:"trace_id,omitempty"`
+}
+
+// HandlerFunc is a gin handler that reports failures as *errcode.Error
+type HandlerFunc func(c *gin.Context) *errcode.Error
+
+func NewResponse(ctx *gin.Context) *Response {
+	return &Response{Ctx: ctx}
+}
+
+// ToErrorResponse writes err with its HTTP status code
+func (r *Response) ToErrorResponse(err *errcode.Error) {
+	r.Ctx.JSON(err.StatusCode(), ErrorResponse{
+		Code:    err.Code(),
+		Msg:     err.Msg(),
+		Details: err.Details(),
+		TraceID: r.Ctx.GetString(tracer.ContextTraceID),
+	})
+}
+
+// Handle adapts h to gin, writing the error response when h fails
+func Handle(h HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h(c); err != nil {
+			NewResponse(c).ToErrorResponse(err)
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"curly-succotash/backend/global"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeShare   = "share"
+)
+
+// Claims identifies the user or AppKey a token was issued to. AppKey tokens
+// act for the user owning the key and carry the key's scopes; user tokens
+// carry none and may use every scope. Share tokens only carry GameID.
+// KeyVersion is the version of the AppKey's secret when the token was
+// issued; rotating the secret invalidates the tokens of older versions.
+type Claims struct {
+	UserID     uint32   `json:"uid,omitempty"`
+	Username   string   `json:"username,omitempty"`
+	AppKey     string   `json:"app_key,omitempty"`
+	KeyVersion uint32   `json:"kver,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	GameID     uint32   `json:"gid,omitempty"`
+	TokenType  string   `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// GetJWTSecret returns the key used to sign and verify tokens
+func GetJWTSecret() []byte {
+	return []byte(global.JWTSetting.Load().Secret)
+}
+
+// GenerateToken signs a token of the given type for a user, expiring after
+// the configured access or refresh lifetime
+func GenerateToken(userID uint32, username, tokenType string) (string, time.Time, error) {
+	s := global.JWTSetting.Load()
+	expire := s.Expire
+	if tokenType == TokenTypeRefresh {
+		expire = s.RefreshExpire
+	}
+	return signToken(Claims{
+		UserID:           userID,
+		Username:         username,
+		TokenType:        tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: username},
+	}, expire)
+}
+
+// GenerateAppToken signs an access token for a version of an AppKey limited
+// to its scopes, acting for the user owning the key
+func GenerateAppToken(appKey string, keyVersion uint32, owner string, scopes []string) (string, time.Time, error) {
+	return signToken(Claims{
+		Username:         owner,
+		AppKey:           appKey,
+		KeyVersion:       keyVersion,
+		Scopes:           scopes,
+		TokenType:        TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: appKey},
+	}, global.JWTSetting.Load().Expire)
+}
+
+// GenerateShareToken signs a read-only token for one game, used in share links
+func GenerateShareToken(gameID uint32, expire time.Duration) (string, time.Time, error) {
+	return signToken(Claims{
+		GameID:           gameID,
+		TokenType:        TokenTypeShare,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: fmt.Sprintf("game:%d", gameID)},
+	}, expire)
+}
+
+func signToken(claims Claims, expire time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(expire)
+	claims.Issuer = global.JWTSetting.Load().Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(GetJWTSecret())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseToken verifies a token and checks that it has the expected type.
+// Expired tokens return an error wrapping jwt.ErrTokenExpired.
+func ParseToken(token, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return GetJWTSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(global.JWTSetting.Load().Issuer))
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("%w: expected %s token, got %q", jwt.ErrTokenInvalidClaims, tokenType, claims.TokenType)
+	}
+	return claims, nil
+}
@@ -6,9 +6,9 @@ import (
 )
 
 type Error struct {
-	code    int      `json:"code"`
-	msg     string   `json:"msg"`
-	details []string `json:"details"`
+	code    int
+	msg     string
+	details []string
 }
 
 var codes = map[int]string{}
@@ -69,6 +69,20 @@ func (e *Error) StatusCode() int {
 		return http.StatusUnauthorized
 	case TooManyRequests.Code():
 		return http.StatusTooManyRequests
+	case Forbidden.Code(), NotGameOwner.Code():
+		return http.StatusForbidden
+	case NotFound.Code(), GameNotFound.Code(), CardNotFound.Code(), RevisionNotFound.Code(), AppKeyNotFound.Code():
+		return http.StatusNotFound
+	case ThemeNotFound.Code(), InvalidCardRows.Code():
+		return http.StatusBadRequest
+	case InvalidCredentials.Code():
+		return http.StatusUnauthorized
+	case UserExists.Code():
+		return http.StatusConflict
+	case BundleTooLarge.Code(), CardsCSVTooLarge.Code():
+		return http.StatusRequestEntityTooLarge
+	case ErrorAIGenerateFail.Code(), ErrorAIResponseInvalid.Code():
+		return http.StatusBadGateway
 	}
 
 	return http.StatusInternalServerError
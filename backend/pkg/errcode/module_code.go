@@ -0,0 +1,68 @@
+package errcode
+
+// Games
+var (
+	GameNotFound           = NewError(20010001, "Game not found")
+	ErrorListGamesFail     = NewError(20010002, "Failed to list games")
+	ErrorGetGameFail       = NewError(20010003, "Failed to get game")
+	ErrorCreateGameFail    = NewError(20010004, "Failed to create game")
+	ErrorForkGameFail      = NewError(20010005, "Failed to fork game")
+	ErrorImportGameFail    = NewError(20010006, "Failed to import game")
+	ErrorUpdateGameFail    = NewError(20010007, "Failed to update game")
+	NotGameOwner           = NewError(20010008, "Only the owner can change this game")
+	ErrorShareGameFail     = NewError(20010009, "Failed to create share link")
+	RevisionNotFound       = NewError(20010010, "Revision not found")
+	ErrorListRevisionsFail = NewError(20010011, "Failed to list revisions")
+	ErrorDiffRevisionsFail = NewError(20010012, "Failed to compare revisions")
+	ErrorRollbackGameFail  = NewError(20010013, "Failed to roll back game")
+)
+
+// Cards
+var (
+	CardNotFound           = NewError(20020001, "Card not found")
+	ErrorGenerateCardsFail = NewError(20020002, "Failed to generate cards")
+	ErrorImportCardsFail   = NewError(20020003, "Failed to import cards")
+	InvalidCardRows        = NewError(20020004, "Invalid rows, no cards were updated")
+	CardsCSVTooLarge       = NewError(20020005, "Card CSV is too large")
+)
+
+// AI
+var (
+	ErrorAIClientFail      = NewError(20030001, "AI service is unavailable")
+	ErrorAIGenerateFail    = NewError(20030002, "AI generation failed")
+	ErrorAIResponseInvalid = NewError(20030003, "AI returned an invalid response")
+)
+
+// Export
+var (
+	ThemeNotFound        = NewError(20040001, "Theme not found")
+	ErrorListThemesFail  = NewError(20040002, "Failed to list themes")
+	ErrorExportGameFail  = NewError(20040003, "Failed to export game")
+	ErrorExportCardsFail = NewError(20040004, "Failed to export cards")
+	ErrorRenderPDFFail   = NewError(20040005, "Failed to render PDF")
+	ErrorRenderImageFail = NewError(20040006, "Failed to render card image")
+	BundleTooLarge       = NewError(20040007, "Game bundle is too large")
+)
+
+// Users and AppKeys
+var (
+	UserExists            = NewError(20050001, "Username already exists")
+	InvalidCredentials    = NewError(20050002, "Invalid username or password")
+	ErrorRegisterFail     = NewError(20050003, "Failed to register user")
+	AppKeyNotFound        = NewError(20050004, "App key not found")
+	ErrorCreateAppKeyFail = NewError(20050005, "Failed to create app key")
+	ErrorListAppKeysFail  = NewError(20050006, "Failed to list app keys")
+	ErrorRotateAppKeyFail = NewError(20050007, "Failed to rotate app key")
+	ErrorRevokeAppKeyFail = NewError(20050008, "Failed to revoke app key")
+)
+
+// Audit
+var (
+	ErrorListAuditLogsFail   = NewError(20060001, "Failed to list audit logs")
+	ErrorExportAuditLogsFail = NewError(20060002, "Failed to export audit logs")
+)
+
+// Usage
+var (
+	ErrorGetUsageFail = NewError(20070001, "Failed to get token usage")
+)
@@ -0,0 +1,205 @@
+package font
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Script identifies the writing system a font is responsible for
+type Script int
+
+const (
+	ScriptLatin Script = iota
+	ScriptHan
+	ScriptJapanese
+	ScriptEmoji
+)
+
+func (s Script) String() string {
+	switch s {
+	case ScriptLatin:
+		return "latin"
+	case ScriptHan:
+		return "han"
+	case ScriptJapanese:
+		return "japanese"
+	case ScriptEmoji:
+		return "emoji"
+	}
+	return ""
+}
+
+// fallbacks lists the scripts tried, in order, when a script has no font of its own
+var fallbacks = map[Script][]Script{
+	ScriptLatin:    {ScriptHan, ScriptJapanese},
+	ScriptHan:      {ScriptJapanese, ScriptLatin},
+	ScriptJapanese: {ScriptHan, ScriptLatin},
+	ScriptEmoji:    {ScriptLatin},
+}
+
+// Face is a TrueType font file registered for a script
+type Face struct {
+	Family string
+	Path   string
+	Script Script
+}
+
+// Registry holds the embeddable fonts, keyed by script
+type Registry struct {
+	faces map[Script]*Face
+}
+
+// NewRegistry loads the TrueType files in files, resolved relative to dir.
+// Scripts with an empty file name are skipped. The registry always holds
+// the fonts that exist; configured files that do not are skipped too and
+// reported in the error, and their scripts use the fallback fonts.
+func NewRegistry(dir string, files map[Script]string) (*Registry, error) {
+	r := &Registry{faces: make(map[Script]*Face)}
+	var missing []string
+	for script := ScriptLatin; script <= ScriptEmoji; script++ {
+		file := files[script]
+		if file == "" {
+			continue
+		}
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, file)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("failed to resolve font %s: %s", file, err))
+			continue
+		}
+		if _, err := os.Stat(abs); err != nil {
+			missing = append(missing, fmt.Sprintf("font for %s not found: %s", script, err))
+			continue
+		}
+		r.faces[script] = &Face{
+			Family: "curly-" + script.String(),
+			Path:   abs,
+			Script: script,
+		}
+	}
+	if len(missing) > 0 {
+		return r, errors.New(strings.Join(missing, "; "))
+	}
+	return r, nil
+}
+
+// Empty reports whether no font has been registered
+func (r *Registry) Empty() bool {
+	return r == nil || len(r.faces) == 0
+}
+
+// Faces returns the registered faces ordered by script
+func (r *Registry) Faces() []*Face {
+	if r == nil {
+		return nil
+	}
+	faces := make([]*Face, 0, len(r.faces))
+	for _, f := range r.faces {
+		faces = append(faces, f)
+	}
+	sort.Slice(faces, func(i, j int) bool { return faces[i].Script < faces[j].Script })
+	return faces
+}
+
+// Face returns the font used to draw the given script, following the
+// fallback chain when the script has no font of its own
+func (r *Registry) Face(s Script) (*Face, bool) {
+	if r.Empty() {
+		return nil, false
+	}
+	if f, ok := r.faces[s]; ok {
+		return f, true
+	}
+	for _, fb := range fallbacks[s] {
+		if f, ok := r.faces[fb]; ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// DetectScript classifies a rune. Runes shared between scripts such as
+// spaces, digits and punctuation are reported as ScriptLatin.
+func DetectScript(c rune) Script {
+	switch {
+	case unicode.In(c, unicode.Hiragana, unicode.Katakana),
+		c >= 0x3040 && c <= 0x30FF: // kana blocks, including the shared ー and ・ marks
+		return ScriptJapanese
+	case unicode.Is(unicode.Han, c),
+		c >= 0x3000 && c <= 0x303F, // CJK symbols and punctuation
+		c >= 0xFF00 && c <= 0xFFEF: // half and full width forms
+		return ScriptHan
+	case isEmoji(c):
+		return ScriptEmoji
+	}
+	return ScriptLatin
+}
+
+func isEmoji(c rune) bool {
+	return (c >= 0x1F300 && c <= 0x1FAFF) || // pictographs, emoticons, transport, supplemental symbols
+		(c >= 0x2600 && c <= 0x27BF) || // miscellaneous symbols and dingbats
+		(c >= 0x1F1E6 && c <= 0x1F1FF) || // regional indicators
+		c == 0xFE0F || c == 0x200D // variation selector and zero width joiner
+}
+
+// Run is a piece of text written in a single script
+type Run struct {
+	Script Script
+	Text   string
+}
+
+// Split breaks text into runs of the same script. Neutral characters
+// (spaces, digits, punctuation) stay in the run they appear in so that
+// font switches only happen where the glyphs actually change.
+func Split(text string) []Run {
+	var runs []Run
+	var b strings.Builder
+	current := ScriptLatin
+	started := false
+	for _, c := range text {
+		s := DetectScript(c)
+		neutral := s == ScriptLatin && !unicode.IsLetter(c)
+		if !started {
+			current = s
+			started = true
+		} else if s != current && !neutral {
+			runs = append(runs, Run{Script: current, Text: b.String()})
+			b.Reset()
+			current = s
+		}
+		b.WriteRune(c)
+	}
+	if b.Len() > 0 {
+		runs = append(runs, Run{Script: current, Text: b.String()})
+	}
+	return runs
+}
+
+// CSS returns the @font-face rules for the registered fonts, referencing the
+// files with file:// URLs so wkhtmltopdf can embed them
+func (r *Registry) CSS() string {
+	var b strings.Builder
+	for _, f := range r.Faces() {
+		fmt.Fprintf(&b, "@font-face { font-family: '%s'; src: url('file://%s') format('truetype'); }\n", f.Family, filepath.ToSlash(f.Path))
+	}
+	return b.String()
+}
+
+// FamilyStack returns a CSS font-family value listing the registered fonts
+// before generic fallbacks, letting the renderer pick a font per glyph
+func (r *Registry) FamilyStack() string {
+	var families []string
+	for _, f := range r.Faces() {
+		families = append(families, fmt.Sprintf("'%s'", f.Family))
+	}
+	families = append(families, "Arial", "sans-serif")
+	return strings.Join(families, ", ")
+}
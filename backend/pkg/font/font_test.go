@@ -0,0 +1,92 @@
+package font
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectScript(t *testing.T) {
+	tests := []struct {
+		c    rune
+		want Script
+	}{
+		{'A', ScriptLatin},
+		{'é', ScriptLatin},
+		{'7', ScriptLatin},
+		{' ', ScriptLatin},
+		{'漢', ScriptHan},
+		{'。', ScriptHan},
+		{'！', ScriptHan},
+		{'ひ', ScriptJapanese},
+		{'カ', ScriptJapanese},
+		{'ー', ScriptJapanese},
+		{'😀', ScriptEmoji},
+		{'♥', ScriptEmoji},
+		{'🇯', ScriptEmoji},
+		{'\u200d', ScriptEmoji},
+		{'\ufe0f', ScriptEmoji},
+	}
+	for _, tt := range tests {
+		if got := DetectScript(tt.c); got != tt.want {
+			t.Errorf("DetectScript(%q) = %s, want %s", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Run
+	}{
+		{"empty", "", nil},
+		{"latin", "Draw 2 cards.", []Run{{ScriptLatin, "Draw 2 cards."}}},
+		{"neutral characters stay in the run", "騎士 2枚。", []Run{{ScriptHan, "騎士 2枚。"}}},
+		{"script change", "Draw 騎士", []Run{{ScriptLatin, "Draw "}, {ScriptHan, "騎士"}}},
+		{"latin after han", "騎士 Knight", []Run{{ScriptHan, "騎士 "}, {ScriptLatin, "Knight"}}},
+		{"japanese kana", "カードを引く", []Run{{ScriptJapanese, "カードを"}, {ScriptHan, "引"}, {ScriptJapanese, "く"}}},
+		{"kana punctuation", "ロール・カード", []Run{{ScriptJapanese, "ロール・カード"}}},
+		{"leading neutral", "1騎士", []Run{{ScriptLatin, "1"}, {ScriptHan, "騎士"}}},
+		{"emoji sequence", "Hi 👩\u200d🚀!", []Run{{ScriptLatin, "Hi "}, {ScriptEmoji, "👩\u200d🚀!"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryFace(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "han.ttf"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRegistry(dir, map[Script]string{ScriptHan: "han.ttf", ScriptEmoji: "missing.ttf"})
+	if err == nil {
+		t.Error("NewRegistry() with a missing file succeeded")
+	}
+
+	tests := []struct {
+		script Script
+		want   Script
+		ok     bool
+	}{
+		{ScriptHan, ScriptHan, true},
+		{ScriptJapanese, ScriptHan, true},
+		{ScriptLatin, ScriptHan, true},
+		{ScriptEmoji, 0, false},
+	}
+	for _, tt := range tests {
+		f, ok := r.Face(tt.script)
+		if ok != tt.ok || ok && f.Script != tt.want {
+			t.Errorf("Face(%s) = %+v, %v, want %s, %v", tt.script, f, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := (*Registry)(nil).Face(ScriptLatin); ok {
+		t.Error("Face() of a nil registry found a font")
+	}
+}
@@ -0,0 +1,56 @@
+package font
+
+import (
+	"github.com/jung-kurt/gofpdf"
+)
+
+// coreFamily is used when no TrueType font has been registered
+const coreFamily = "Arial"
+
+// RegisterPDF embeds the registered fonts into the document. Each font is
+// registered for the regular and bold styles because gofpdf cannot
+// synthesize bold glyphs for UTF-8 fonts.
+func (r *Registry) RegisterPDF(pdf *gofpdf.Fpdf) {
+	for _, f := range r.Faces() {
+		pdf.AddUTF8Font(f.Family, "", f.Path)
+		pdf.AddUTF8Font(f.Family, "B", f.Path)
+	}
+}
+
+// SetPDFFont selects the font for the given script, falling back to the
+// core Arial font when nothing suitable is registered
+func (r *Registry) SetPDFFont(pdf *gofpdf.Fpdf, s Script, style string, size float64) {
+	if f, ok := r.Face(s); ok {
+		pdf.SetFont(f.Family, style, size)
+		return
+	}
+	pdf.SetFont(coreFamily, style, size)
+}
+
+// WritePDF writes flowing text at the current position, switching fonts
+// between script runs. Text wraps at the document's current margins.
+func (r *Registry) WritePDF(pdf *gofpdf.Fpdf, style string, size, lineHeight float64, text string) {
+	if r.Empty() {
+		pdf.SetFont(coreFamily, style, size)
+		pdf.Write(lineHeight, text)
+		return
+	}
+	for _, run := range Split(text) {
+		r.SetPDFFont(pdf, run.Script, style, size)
+		pdf.Write(lineHeight, run.Text)
+	}
+}
+
+// MultiCellPDF writes text inside a box of width w starting at (x, y),
+// wrapping within the box and switching fonts per script run. It restores
+// the document margins afterwards.
+func (r *Registry) MultiCellPDF(pdf *gofpdf.Fpdf, x, y, w float64, style string, size, lineHeight float64, text string) {
+	left, top, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.SetLeftMargin(x)
+	pdf.SetRightMargin(pageWidth - x - w)
+	pdf.SetXY(x, y)
+	r.WritePDF(pdf, style, size, lineHeight, text)
+	pdf.Ln(lineHeight)
+	pdf.SetMargins(left, top, right)
+}
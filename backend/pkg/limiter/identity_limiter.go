@@ -0,0 +1,49 @@
+package limiter
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRule is the rule key applied to routes without a rule of their own
+const DefaultRule = "*"
+
+// keySeparator joins the route and the identity in bucket keys
+const keySeparator = "|"
+
+// IdentityLimiter keeps a bucket per route and caller. Rules are keyed by
+// the route pattern (e.g. "/api/v1/games/:id/fork") and each caller gets
+// its own bucket, created on first use.
+type IdentityLimiter struct {
+	*Limiter
+	identity func(c *gin.Context) string
+}
+
+// NewIdentityLimiter creates a limiter identifying callers with identity,
+// such as the signed-in user or the client IP
+func NewIdentityLimiter(store Store, identity func(c *gin.Context) string) LimiterIface {
+	return IdentityLimiter{
+		Limiter:  newLimiter(store),
+		identity: identity,
+	}
+}
+
+func (l IdentityLimiter) Key(c *gin.Context) string {
+	route := c.FullPath()
+	if _, ok := l.rules[route]; !ok {
+		route = DefaultRule
+	}
+	return route + keySeparator + l.identity(c)
+}
+
+func (l IdentityLimiter) Rule(key string) (LimiterBucketRule, bool) {
+	route, _, _ := strings.Cut(key, keySeparator)
+	return l.Limiter.Rule(route)
+}
+
+func (l IdentityLimiter) AddBuckets(rules ...LimiterBucketRule) LimiterIface {
+	l.addRules(rules...)
+
+	return l
+}
@@ -1,20 +1,22 @@
 package limiter
 
 import (
+	"context"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
 )
 
 type LimiterIface interface {
 	Key(c *gin.Context) string
-	GetBucket(key string) (*ratelimit.Bucket, bool)
+	Rule(key string) (LimiterBucketRule, bool)
+	Take(ctx context.Context, key string, rule LimiterBucketRule) (Result, error)
 	AddBuckets(rules ...LimiterBucketRule) LimiterIface
 }
 
 type Limiter struct {
-	limiterBuckets map[string]*ratelimit.Bucket
+	rules map[string]LimiterBucketRule
+	store Store
 }
 
 type LimiterBucketRule struct {
@@ -23,3 +25,38 @@ type LimiterBucketRule struct {
 	Capacity     int64
 	Quantum      int64
 }
+
+// Result reports the state of a bucket after taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets of a limiter. Buckets are created from the
+// rule on first use; Take removes one token from the bucket if there is one.
+type Store interface {
+	Take(ctx context.Context, key string, rule LimiterBucketRule) (Result, error)
+}
+
+func newLimiter(store Store) *Limiter {
+	return &Limiter{rules: make(map[string]LimiterBucketRule), store: store}
+}
+
+func (l *Limiter) Rule(key string) (LimiterBucketRule, bool) {
+	rule, ok := l.rules[key]
+	return rule, ok
+}
+
+func (l *Limiter) Take(ctx context.Context, key string, rule LimiterBucketRule) (Result, error) {
+	return l.store.Take(ctx, key, rule)
+}
+
+func (l *Limiter) addRules(rules ...LimiterBucketRule) {
+	for _, rule := range rules {
+		if _, ok := l.rules[rule.Key]; !ok {
+			l.rules[rule.Key] = rule
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var testRule = LimiterBucketRule{Key: "/test", FillInterval: time.Minute, Capacity: 3, Quantum: 2}
+
+// testSQLStore returns a store on a new SQLite database whose clock is
+// returned for the test to move
+func testSQLStore(t *testing.T) (*SQLStore, *time.Time) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "limits.db")+"?_busy_timeout=5000"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&RateLimitBucket{}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	store := NewSQLStore(db)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+// takeN takes n tokens and returns the results
+func takeN(t *testing.T, store Store, key string, n int) []Result {
+	t.Helper()
+	results := make([]Result, n)
+	for i := range results {
+		var err error
+		if results[i], err = store.Take(context.Background(), key, testRule); err != nil {
+			t.Fatalf("Take #%d: %v", i+1, err)
+		}
+	}
+	return results
+}
+
+func TestSQLStoreCapacity(t *testing.T) {
+	store, _ := testSQLStore(t)
+	results := takeN(t, store, "a", 4)
+	for i, want := range []int64{2, 1, 0} {
+		if !results[i].Allowed || results[i].Remaining != want || results[i].Limit != 3 {
+			t.Errorf("take #%d = %+v, want allowed with %d remaining", i+1, results[i], want)
+		}
+	}
+	if r := results[3]; r.Allowed || r.RetryAfter != time.Minute {
+		t.Errorf("take #4 = %+v, want denied with a retry after the next minute", r)
+	}
+	if r := takeN(t, store, "b", 1)[0]; !r.Allowed || r.Remaining != 2 {
+		t.Errorf("take on another key = %+v, want a full bucket", r)
+	}
+}
+
+func TestSQLStoreRefill(t *testing.T) {
+	store, now := testSQLStore(t)
+	takeN(t, store, "a", 3)
+
+	*now = now.Add(30 * time.Second)
+	if r := takeN(t, store, "a", 1)[0]; r.Allowed || r.RetryAfter != 30*time.Second {
+		t.Errorf("take within the tick = %+v, want denied for 30s", r)
+	}
+
+	// One tick refills a quantum of 2
+	*now = now.Add(30 * time.Second)
+	results := takeN(t, store, "a", 3)
+	if !results[0].Allowed || !results[1].Allowed || results[1].Remaining != 0 || results[2].Allowed {
+		t.Errorf("takes after a tick = %+v, want 2 allowed", results)
+	}
+
+	// Refills stop at the capacity
+	*now = now.Add(time.Hour)
+	results = takeN(t, store, "a", 4)
+	if !results[0].Allowed || results[0].Remaining != 2 || results[3].Allowed {
+		t.Errorf("takes after an hour = %+v, want a full bucket of 3", results)
+	}
+}
+
+func TestSQLStoreRetriesConcurrentUpdates(t *testing.T) {
+	store, _ := testSQLStore(t)
+	takeN(t, store, "a", 1)
+
+	// Take a token behind the store's back between its read and its update
+	interfered := 0
+	err := store.db.Callback().Update().Before("gorm:update").Register("test:interfere", func(db *gorm.DB) {
+		if interfered > 0 {
+			return
+		}
+		interfered++
+		if err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Exec("UPDATE rate_limits SET tokens = tokens - 1 WHERE bucket_key = ?", "a").Error; err != nil {
+			t.Errorf("interfering update: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := takeN(t, store, "a", 1)[0]
+	if interfered != 1 || !r.Allowed || r.Remaining != 0 {
+		t.Errorf("take after a concurrent update = %+v, want allowed with 0 remaining", r)
+	}
+	if r := takeN(t, store, "a", 1)[0]; r.Allowed {
+		t.Errorf("take of an empty bucket = %+v, want denied", r)
+	}
+}
+
+func TestSQLStoreConcurrentTakes(t *testing.T) {
+	store, _ := testSQLStore(t)
+	rule := LimiterBucketRule{Key: "/test", FillInterval: time.Hour, Capacity: 10, Quantum: 1}
+
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := store.Take(context.Background(), "a", rule)
+			if err != nil && !errors.Is(err, ErrStoreConflict) {
+				t.Errorf("Take: %v", err)
+			}
+			if r.Allowed && err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed > 10 {
+		t.Errorf("%d concurrent takes allowed, want at most the capacity of 10", allowed)
+	}
+}
+
+func TestSQLStorePrune(t *testing.T) {
+	store, now := testSQLStore(t)
+	takeN(t, store, "idle", 1)
+	*now = now.Add(sqlStoreIdleTTL - time.Minute)
+	takeN(t, store, "recent", 1)
+
+	// The first takes pruned nothing; the next prune is an interval later
+	*now = now.Add(sqlStorePruneInterval)
+	takeN(t, store, "recent", 1)
+
+	var keys []string
+	if err := store.db.Model(&RateLimitBucket{}).Order("bucket_key").Pluck("bucket_key", &keys).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "recent" {
+		t.Errorf("buckets after pruning = %v, want [recent]", keys)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	results := takeN(t, store, "a", 4)
+	if !results[0].Allowed || results[0].Remaining != 2 || !results[2].Allowed || results[3].Allowed {
+		t.Errorf("takes = %+v, want 3 allowed", results)
+	}
+	if results[3].RetryAfter != testRule.FillInterval {
+		t.Errorf("RetryAfter = %v, want %v", results[3].RetryAfter, testRule.FillInterval)
+	}
+	store.bucket("full", testRule)
+
+	store.mu.Lock()
+	store.prune(time.Now().Add(memoryStorePruneInterval))
+	_, keptUsed := store.buckets["a"]
+	_, keptFull := store.buckets["full"]
+	store.mu.Unlock()
+	if !keptUsed || keptFull {
+		t.Errorf("after pruning, kept a = %v and full = %v, want only a", keptUsed, keptFull)
+	}
+}
+
+func TestIdentityLimiter(t *testing.T) {
+	l := NewIdentityLimiter(NewMemoryStore(), func(c *gin.Context) string {
+		return c.GetHeader("X-User")
+	}).AddBuckets(
+		LimiterBucketRule{Key: DefaultRule, FillInterval: time.Second, Capacity: 100, Quantum: 1},
+		LimiterBucketRule{Key: "/games/:id", FillInterval: time.Minute, Capacity: 1, Quantum: 1},
+	)
+
+	r := gin.New()
+	keys := map[string]string{}
+	handler := func(c *gin.Context) { keys[c.Request.URL.Path+" "+c.GetHeader("X-User")] = l.Key(c) }
+	r.GET("/games/:id", handler)
+	r.GET("/themes", handler)
+	for _, req := range []struct{ path, user string }{{"/games/1", "alice"}, {"/games/2", "alice"}, {"/games/1", "bob"}, {"/themes", "alice"}} {
+		httpReq := httptest.NewRequest("GET", req.path, nil)
+		httpReq.Header.Set("X-User", req.user)
+		r.ServeHTTP(httptest.NewRecorder(), httpReq)
+	}
+
+	want := map[string]string{
+		"/games/1 alice": "/games/:id|alice",
+		"/games/2 alice": "/games/:id|alice",
+		"/games/1 bob":   "/games/:id|bob",
+		"/themes alice":  "*|alice",
+	}
+	for req, key := range want {
+		if keys[req] != key {
+			t.Errorf("Key(%s) = %q, want %q", req, keys[req], key)
+		}
+	}
+	if rule, ok := l.Rule("/games/:id|alice"); !ok || rule.Capacity != 1 {
+		t.Errorf("Rule(/games/:id|alice) = %+v, %v, want the /games/:id rule", rule, ok)
+	}
+	if rule, ok := l.Rule("*|alice"); !ok || rule.Capacity != 100 {
+		t.Errorf("Rule(*|alice) = %+v, %v, want the default rule", rule, ok)
+	}
+
+	ctx := context.Background()
+	if r, _ := l.Take(ctx, "/games/:id|alice", testRule); !r.Allowed {
+		t.Errorf("first take for alice = %+v, want allowed", r)
+	}
+	if r, _ := l.Take(ctx, "/games/:id|bob", testRule); !r.Allowed || r.Remaining != 2 {
+		t.Errorf("first take for bob = %+v, want a bucket of his own", r)
+	}
+}
@@ -0,0 +1,66 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+)
+
+// memoryStorePruneInterval is how often a store drops refilled buckets
+const memoryStorePruneInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory. Each replica of the server
+// has its own buckets, so use SQLStore when running more than one.
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*ratelimit.Bucket
+	prunedOn time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*ratelimit.Bucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, rule LimiterBucketRule) (Result, error) {
+	bucket := s.bucket(key, rule)
+	result := Result{
+		Allowed: bucket.TakeAvailable(1) == 1,
+		Limit:   bucket.Capacity(),
+	}
+	result.Remaining = max(bucket.Available(), 0)
+	if !result.Allowed {
+		// juju/ratelimit does not expose its clock, so report the longest wait
+		result.RetryAfter = rule.FillInterval
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) bucket(key string, rule LimiterBucketRule) *ratelimit.Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Capacity, rule.Quantum)
+		s.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// prune drops the buckets that have refilled to capacity, at most once per
+// memoryStorePruneInterval. A full bucket is the same as a new one, so
+// dropping it does not change any limit. The caller holds s.mu.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.prunedOn) < memoryStorePruneInterval {
+		return
+	}
+	s.prunedOn = now
+	for key, bucket := range s.buckets {
+		if bucket.Available() >= bucket.Capacity() {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -4,16 +4,15 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
 )
 
 type MethodLimiter struct {
 	*Limiter
 }
 
-func NewMethodLimiter() LimiterIface {
+func NewMethodLimiter(store Store) LimiterIface {
 	return MethodLimiter{
-		Limiter: &Limiter{limiterBuckets: make(map[string]*ratelimit.Bucket)},
+		Limiter: newLimiter(store),
 	}
 }
 
@@ -27,17 +26,8 @@ func (l MethodLimiter) Key(c *gin.Context) string {
 	return uri[:index]
 }
 
-func (l MethodLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
-	bucket, ok := l.limiterBuckets[key]
-	return bucket, ok
-}
-
 func (l MethodLimiter) AddBuckets(rules ...LimiterBucketRule) LimiterIface {
-	for _, rule := range rules {
-		if _, ok := l.limiterBuckets[rule.Key]; !ok {
-			l.limiterBuckets[rule.Key] = ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Capacity, rule.Quantum)
-		}
-	}
+	l.addRules(rules...)
 
 	return l
 }
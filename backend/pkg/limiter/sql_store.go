@@ -0,0 +1,127 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// sqlStoreRetries bounds the optimistic update attempts of a take
+	sqlStoreRetries = 5
+	// sqlStorePruneInterval is how often a store deletes idle buckets
+	sqlStorePruneInterval = time.Hour
+	// sqlStoreIdleTTL is how long a bucket may go unused before it is
+	// deleted. Buckets refilling within this time are full by then, so
+	// deleting them does not change any limit.
+	sqlStoreIdleTTL = 24 * time.Hour
+)
+
+// ErrStoreConflict indicates a bucket kept changing under concurrent takes
+var ErrStoreConflict = errors.New("limiter: too many concurrent updates")
+
+// RateLimitBucket is a token bucket row of SQLStore
+type RateLimitBucket struct {
+	Key       string `gorm:"column:bucket_key;type:varchar(191);primaryKey"`
+	Tokens    int64  `gorm:"not null"`
+	Tick      int64  `gorm:"not null"`
+	UpdatedOn int64  `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for RateLimitBucket
+func (RateLimitBucket) TableName() string {
+	return "rate_limits"
+}
+
+// SQLStore keeps buckets in the rate_limits table so every replica sharing
+// the database shares the limits. Buckets refill rule.Quantum tokens at
+// each rule.FillInterval tick of the wall clock, and takes use a
+// compare-and-swap update so concurrent requests never overdraw a bucket.
+type SQLStore struct {
+	db *gorm.DB
+	// now is the clock driving refills, replaced in tests
+	now func() time.Time
+
+	mu       sync.Mutex
+	prunedOn time.Time
+}
+
+func NewSQLStore(db *gorm.DB) *SQLStore {
+	return &SQLStore{db: db, now: time.Now}
+}
+
+func (s *SQLStore) Take(ctx context.Context, key string, rule LimiterBucketRule) (Result, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+	tick := now.UnixNano() / int64(rule.FillInterval)
+	result := Result{Limit: rule.Capacity}
+
+	s.prune(db, now)
+
+	bucket := RateLimitBucket{Key: key, Tokens: rule.Capacity, Tick: tick, UpdatedOn: now.Unix()}
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket).Error
+	if err != nil {
+		return result, err
+	}
+
+	for range sqlStoreRetries {
+		var current RateLimitBucket
+		if err := db.Where("bucket_key = ?", key).First(&current).Error; err != nil {
+			return result, err
+		}
+
+		tokens := current.Tokens
+		if tick > current.Tick {
+			ticks := tick - current.Tick
+			if ticks >= (rule.Capacity-tokens)/rule.Quantum+1 {
+				tokens = rule.Capacity
+			} else {
+				tokens = min(tokens+ticks*rule.Quantum, rule.Capacity)
+			}
+		}
+		result.Allowed = tokens > 0
+		if result.Allowed {
+			tokens--
+		}
+		result.Remaining = tokens
+		if !result.Allowed {
+			result.RetryAfter = time.Unix(0, (max(tick, current.Tick)+1)*int64(rule.FillInterval)).Sub(now)
+		}
+		if tokens == current.Tokens && tick <= current.Tick {
+			return result, nil
+		}
+
+		update := db.Model(&RateLimitBucket{}).
+			Where("bucket_key = ? AND tokens = ? AND tick = ?", key, current.Tokens, current.Tick).
+			Updates(map[string]any{
+				"tokens":     tokens,
+				"tick":       max(tick, current.Tick),
+				"updated_on": now.Unix(),
+			})
+		if update.Error != nil {
+			return result, update.Error
+		}
+		if update.RowsAffected == 1 {
+			return result, nil
+		}
+	}
+	return result, ErrStoreConflict
+}
+
+// prune deletes idle buckets at most once per sqlStorePruneInterval
+func (s *SQLStore) prune(db *gorm.DB, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.prunedOn) < sqlStorePruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.prunedOn = now
+	s.mu.Unlock()
+
+	// Pruning is best effort; a failure only leaves idle rows behind
+	db.Where("updated_on < ?", now.Add(-sqlStoreIdleTTL).Unix()).Delete(&RateLimitBucket{})
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"curly-succotash/backend/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type attrsKey struct{}
+
+// With returns a context whose records carry args, given as slog.Attr or
+// alternating keys and values like slog.Logger.With. Middleware uses it for
+// request-scoped attributes such as the user and the game ID.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs := make([]slog.Attr, len(prev), len(prev)+len(args))
+	copy(attrs, prev)
+	for len(args) > 0 {
+		switch a := args[0].(type) {
+		case slog.Attr:
+			attrs, args = append(attrs, a), args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs, args = append(attrs, slog.String("!BADKEY", a)), nil
+				continue
+			}
+			attrs, args = append(attrs, slog.Any(a, args[1])), args[2:]
+		default:
+			attrs, args = append(attrs, slog.Any("!BADKEY", a)), args[1:]
+		}
+	}
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextAttrs returns the attributes added with With and the trace and span
+// IDs of ctx
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ginCtx, ok := ctx.(*gin.Context); ok && ginCtx.Request != nil {
+		ctx = ginCtx.Request.Context()
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	if tc, ok := tracer.FromContext(ctx); ok {
+		attrs = append(attrs[:len(attrs):len(attrs)], slog.String("trace_id", tc.TraceID), slog.String("span_id", tc.SpanID))
+	}
+	return attrs
+}
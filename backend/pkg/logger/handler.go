@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// handlerOptions are shared by the handlers: the Logger filters levels, so
+// the handlers accept everything, and replaceAttr names levels and redacts
+// secrets
+var handlerOptions = &slog.HandlerOptions{
+	AddSource:   true,
+	Level:       slog.LevelDebug,
+	ReplaceAttr: replaceAttr,
+}
+
+// NewJSONHandler returns a handler writing a JSON object per line, for log
+// files and log collectors
+func NewJSONHandler(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, handlerOptions)
+}
+
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.LevelKey:
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String(slog.LevelKey, levelOf(level).String())
+			}
+		case slog.SourceKey:
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				return slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", src.File, src.Line))
+			}
+			return a
+		}
+	}
+	return redactAttr(a)
+}
+
+// multiHandler writes each record to every handler
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
+
+// prettyHandler writes colored single-line records for reading in a
+// terminal during development:
+//
+//	15:04:05.000 INFO  Serving on port 8080 trace_id=4bf9... (main.go:112)
+type prettyHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  string
+	prefix string
+}
+
+// NewPrettyHandler returns a handler writing human-readable colored lines
+func NewPrettyHandler(w io.Writer) slog.Handler {
+	return &prettyHandler{mu: new(sync.Mutex), w: w}
+}
+
+var levelColors = map[Level]string{
+	LevelDebug: "\033[90m",
+	LevelInfo:  "\033[36m",
+	LevelWarn:  "\033[33m",
+	LevelError: "\033[31m",
+	LevelFatal: "\033[1;31m",
+	LevelPanic: "\033[1;31m",
+}
+
+const colorReset = "\033[0m"
+
+func (h *prettyHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *prettyHandler) Handle(_ context.Context, r slog.Record) error {
+	level := levelOf(r.Level)
+	buf := new(bytes.Buffer)
+	buf.WriteString(r.Time.Format("15:04:05.000"))
+	fmt.Fprintf(buf, " %s%-5s%s ", levelColors[level], strings.ToUpper(level.String()), colorReset)
+	buf.WriteString(redactString(r.Message))
+	buf.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(buf, h.prefix, a)
+		return true
+	})
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		fmt.Fprintf(buf, " \033[90m(%s:%d)%s", filepath.Base(frame.File), frame.Line, colorReset)
+	}
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	buf := bytes.NewBufferString(h.attrs)
+	for _, a := range attrs {
+		writeAttr(buf, h.prefix, a)
+	}
+	hh := *h
+	hh.attrs = buf.String()
+	return &hh
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	hh := *h
+	hh.prefix += name + "."
+	return &hh
+}
+
+// writeAttr writes a as " key=value", flattening groups into dotted keys
+func writeAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(buf, prefix, ga)
+		}
+		return
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	a = redactAttr(a)
+	value := a.Value.String()
+	if a.Value.Kind() == slog.KindTime {
+		value = a.Value.Time().Format(time.RFC3339)
+	}
+	if value == "" || strings.ContainsAny(value, " =\"\n") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(buf, " \033[90m%s%s=%s%s", prefix, a.Key, colorReset, value)
+}
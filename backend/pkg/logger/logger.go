@@ -2,14 +2,12 @@ package logger
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
-
-	"github.com/gin-gonic/gin"
 )
 
 type Level int8
@@ -43,161 +41,220 @@ func (l Level) String() string {
 	return ""
 }
 
-type Logger struct {
-	newLogger *log.Logger
-	ctx       context.Context
-	fields    Fields
-	callers   []string
+// ParseLevel returns the level named s, as returned by Level.String
+func ParseLevel(s string) (Level, error) {
+	for l := LevelDebug; l <= LevelPanic; l++ {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return LevelDebug, fmt.Errorf("unknown log level: %s", s)
 }
 
-func NewLogger(w io.Writer, prefix string, flag int) *Logger {
-	l := log.New(w, prefix, flag)
-	return &Logger{newLogger: l}
+// slogLevels maps the levels to slog levels; fatal and panic sit above
+// slog.LevelError
+var slogLevels = [...]slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, slog.LevelError + 4, slog.LevelError + 8}
+
+func (l Level) slog() slog.Level {
+	return slogLevels[l]
 }
 
-func (l *Logger) clone() *Logger {
-	nl := *l
-	return &nl
+// levelOf returns the Level of a slog level, rounding down
+func levelOf(level slog.Level) Level {
+	for l := LevelPanic; l > LevelDebug; l-- {
+		if level >= l.slog() {
+			return l
+		}
+	}
+	return LevelDebug
 }
 
-func (l *Logger) WithFields(f Fields) *Logger {
-	ll := l.clone()
-	if ll.fields == nil {
-		ll.fields = make(Fields)
+// Logger writes leveled records to slog handlers. Records carry the fields
+// of WithFields, the attributes added to the context with With, and the
+// trace and span IDs of the context.
+type Logger struct {
+	handler slog.Handler
+	attrs   []slog.Attr
+	// levels are shared by the loggers derived from the same NewLogger
+	levels *atomic.Pointer[Levels]
+}
+
+// Levels are the minimum levels of logged messages: Default, unless the
+// calling package matches a key of Packages. Keys are package paths
+// relative to the module, e.g. "internal/service", and also match their
+// subpackages; the longest matching key wins.
+type Levels struct {
+	Default  Level
+	Packages map[string]Level
+}
+
+// NewLogger returns a Logger writing every record to each of handlers, such
+// as the ones returned by NewJSONHandler and NewPrettyHandler
+func NewLogger(handlers ...slog.Handler) *Logger {
+	levels := new(atomic.Pointer[Levels])
+	levels.Store(&Levels{Default: LevelDebug})
+	var handler slog.Handler = multiHandler(handlers)
+	if len(handlers) == 1 {
+		handler = handlers[0]
 	}
-	for k, v := range f {
-		ll.fields[k] = v
+	return &Logger{handler: handler, levels: levels}
+}
+
+// SetLevel drops messages below level, keeping the package levels. It
+// applies to every logger derived from the same NewLogger and is safe to
+// call while logging.
+func (l *Logger) SetLevel(level Level) {
+	levels := l.Levels()
+	levels.Default = level
+	l.SetLevels(levels)
+}
+
+// SetLevels replaces the default and package levels
+func (l *Logger) SetLevels(levels Levels) {
+	packages := make(map[string]Level, len(levels.Packages))
+	for pkg, level := range levels.Packages {
+		packages[strings.Trim(pkg, "/")] = level
 	}
-	return ll
+	l.levels.Store(&Levels{Default: levels.Default, Packages: packages})
 }
 
-func (l *Logger) WithContext(ctx context.Context) *Logger {
-	ll := l.clone()
-	ll.ctx = ctx
-	return ll
+// Level returns the default minimum level of logged messages
+func (l *Logger) Level() Level {
+	return l.levels.Load().Default
 }
 
-func (l *Logger) WithCaller(skip int) *Logger {
-	ll := l.clone()
-	pc, file, line, ok := runtime.Caller(skip)
-	if ok {
-		f := runtime.FuncForPC(pc)
-		ll.callers = []string{fmt.Sprintf("%s: %d %s", file, line, f.Name())}
+// Levels returns a copy of the default and package levels
+func (l *Logger) Levels() Levels {
+	levels := l.levels.Load()
+	packages := make(map[string]Level, len(levels.Packages))
+	for pkg, level := range levels.Packages {
+		packages[pkg] = level
 	}
+	return Levels{Default: levels.Default, Packages: packages}
+}
 
-	return ll
-}
-
-func (l *Logger) WithCallersFrames() *Logger {
-	maxCallerDepth := 25
-	minCallerDepth := 1
-	callers := []string{}
-	pcs := make([]uintptr, maxCallerDepth)
-	depth := runtime.Callers(minCallerDepth, pcs)
-	frames := runtime.CallersFrames(pcs[:depth])
-	for frame, more := frames.Next(); more; frame, more = frames.Next() {
-		s := fmt.Sprintf("%s: %d %s", frame.File, frame.Line, frame.Function)
-		callers = append(callers, s)
-		if !more {
-			break
-		}
+// enabled reports whether a message of level logged by the function skip
+// frames above enabled's caller passes the levels
+func (l *Logger) enabled(level Level, skip int) bool {
+	levels := l.levels.Load()
+	if len(levels.Packages) == 0 {
+		return level >= levels.Default
 	}
-	ll := l.clone()
-	ll.callers = callers
-	return ll
-}
-
-func (l *Logger) WithTrace() *Logger {
-	ginCtx, ok := l.ctx.(*gin.Context)
-	if ok {
-		return l.WithFields(Fields{
-			"trace_id": ginCtx.MustGet("X-Trace-ID"),
-			"span_id":  ginCtx.MustGet("X-Span-ID"),
-		})
+	min := levels.Default
+	if pc, _, _, ok := runtime.Caller(skip + 2); ok {
+		if f := runtime.FuncForPC(pc); f != nil {
+			min = levels.forPackage(packagePath(f.Name()))
+		}
 	}
-	return l
-}
-
-func (l *Logger) JSONFormat(level Level, message string) map[string]interface{} {
-	data := make(Fields, len(l.fields)+4)
-	data["level"] = level.String()
-	data["time"] = time.Now().Local().UnixNano()
-	data["message"] = message
-	data["callers"] = l.callers
-	if len(l.fields) > 0 {
-		for k, v := range l.fields {
-			if _, ok := data[k]; !ok {
-				data[k] = v
-			}
+	return level >= min
+}
+
+// forPackage returns the level of the longest package key matching pkg
+func (levels *Levels) forPackage(pkg string) Level {
+	min, matched := levels.Default, -1
+	for key, level := range levels.Packages {
+		if len(key) <= matched {
+			continue
+		}
+		if i := strings.Index(pkg, key); i >= 0 && (i == 0 || pkg[i-1] == '/') &&
+			(len(pkg) == i+len(key) || pkg[i+len(key)] == '/') {
+			min, matched = level, len(key)
 		}
 	}
+	return min
+}
 
-	return data
+// packagePath returns the import path of the package of a function named as
+// by runtime.Func.Name, e.g. "curly-succotash/backend/internal/service"
+func packagePath(funcName string) string {
+	slash := strings.LastIndex(funcName, "/")
+	dot := strings.Index(funcName[slash+1:], ".")
+	if dot < 0 {
+		return funcName
+	}
+	return funcName[:slash+1+dot]
 }
 
-func (l *Logger) Output(level Level, message string) {
-	body, _ := json.Marshal(l.JSONFormat(level, message))
-	content := string(body)
-	switch level {
-	case LevelDebug:
-		l.newLogger.Print(content)
-	case LevelInfo:
-		l.newLogger.Print(content)
-	case LevelWarn:
-		l.newLogger.Print(content)
-	case LevelError:
-		l.newLogger.Print(content)
-	case LevelFatal:
-		l.newLogger.Fatal(content)
-	case LevelPanic:
-		l.newLogger.Panic(content)
+// WithFields returns a Logger adding f to every record
+func (l *Logger) WithFields(f Fields) *Logger {
+	ll := *l
+	ll.attrs = make([]slog.Attr, 0, len(l.attrs)+len(f))
+	ll.attrs = append(ll.attrs, l.attrs...)
+	for k, v := range f {
+		ll.attrs = append(ll.attrs, slog.Any(k, v))
+	}
+	return &ll
+}
+
+// log writes a record for the caller of the level method. Fatal records do
+// not exit: handlers must not stop the server, and main decides how to exit
+// on startup errors. Panic records panic with the message after logging,
+// which the recovery middleware turns into an error response.
+func (l *Logger) log(ctx context.Context, level Level, message string) {
+	if !l.enabled(level, 1) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if l.handler.Enabled(ctx, level.slog()) {
+		// Skip runtime.Callers, log and the level method
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		r := slog.NewRecord(time.Now(), level.slog(), message, pcs[0])
+		r.AddAttrs(l.attrs...)
+		r.AddAttrs(contextAttrs(ctx)...)
+		_ = l.handler.Handle(ctx, r)
+	}
+	if level == LevelPanic {
+		panic(message)
 	}
 }
 
 func (l *Logger) Debug(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelDebug, fmt.Sprint(v...))
+	l.log(ctx, LevelDebug, fmt.Sprint(v...))
 }
 
 func (l *Logger) Debugf(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelDebug, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelDebug, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Info(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelInfo, fmt.Sprint(v...))
+	l.log(ctx, LevelInfo, fmt.Sprint(v...))
 }
 
 func (l *Logger) Infof(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelInfo, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelInfo, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Warn(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelWarn, fmt.Sprint(v...))
+	l.log(ctx, LevelWarn, fmt.Sprint(v...))
 }
 
 func (l *Logger) Warnf(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelWarn, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelWarn, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Error(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelError, fmt.Sprint(v...))
+	l.log(ctx, LevelError, fmt.Sprint(v...))
 }
 
 func (l *Logger) Errorf(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelError, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelError, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Fatal(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelFatal, fmt.Sprint(v...))
+	l.log(ctx, LevelFatal, fmt.Sprint(v...))
 }
 
 func (l *Logger) Fatalf(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelFatal, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelFatal, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Panic(ctx context.Context, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelPanic, fmt.Sprint(v...))
+	l.log(ctx, LevelPanic, fmt.Sprint(v...))
 }
 
 func (l *Logger) Panicf(ctx context.Context, format string, v ...interface{}) {
-	l.WithContext(ctx).WithTrace().Output(LevelPanic, fmt.Sprintf(format, v...))
+	l.log(ctx, LevelPanic, fmt.Sprintf(format, v...))
 }
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted replaces secrets and prompts in records
+const Redacted = "[REDACTED]"
+
+// redactedKeys are attribute key suffixes whose values are always redacted.
+// Prompts are redacted as they may hold user content and the game's story.
+var redactedKeys = []string{"prompt", "api_key", "apikey", "password", "secret", "token", "authorization"}
+
+// secrets are values redacted wherever they appear in messages and string
+// attributes, see SetSecrets
+var secrets atomic.Pointer[[]string]
+
+// minSecretLen keeps short placeholder values from mangling unrelated text
+const minSecretLen = 6
+
+// SetSecrets replaces the values redacted from messages and attributes, such
+// as API keys and passwords read from the config. Values shorter than six
+// characters are ignored.
+func SetSecrets(values ...string) {
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if len(v) >= minSecretLen {
+			list = append(list, v)
+		}
+	}
+	secrets.Store(&list)
+}
+
+func redactedKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range redactedKeys {
+		if strings.HasSuffix(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the secrets in s
+func redactString(s string) string {
+	list := secrets.Load()
+	if list == nil {
+		return s
+	}
+	for _, secret := range *list {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if redactedKey(a.Key) && !a.Value.Equal(slog.StringValue("")) {
+		return slog.String(a.Key, Redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, redactString(err.Error()))
+		}
+	}
+	return a
+}
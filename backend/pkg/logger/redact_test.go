@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestRedactAttr(t *testing.T) {
+	SetSecrets("sk-live-123456", "short", "")
+	t.Cleanup(func() { SetSecrets() })
+
+	tests := []struct {
+		name string
+		in   slog.Attr
+		want slog.Value
+	}{
+		{"secret key", slog.String("jwt_secret", "anything"), slog.StringValue(Redacted)},
+		{"key suffix is case insensitive", slog.String("OpenAI_APIKey", "anything"), slog.StringValue(Redacted)},
+		{"prompt", slog.String("story_prompt", "Once upon a time"), slog.StringValue(Redacted)},
+		{"non-string under secret key", slog.Int("retry_token", 3), slog.StringValue(Redacted)},
+		{"empty secret key", slog.String("password", ""), slog.StringValue("")},
+		{"secret value", slog.String("url", "https://api?key=sk-live-123456"), slog.StringValue("https://api?key=" + Redacted)},
+		{"secret in error", slog.Any("error", errors.New("bad key sk-live-123456")), slog.StringValue("bad key " + Redacted)},
+		{"short values are kept", slog.String("msg", "short"), slog.StringValue("short")},
+		{"plain", slog.Int("cards", 12), slog.IntValue(12)},
+		{"prompt in the middle of a key", slog.String("prompt_tokens", "12"), slog.StringValue("12")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactAttr(tt.in)
+			if got.Key != tt.in.Key || !got.Value.Equal(tt.want) {
+				t.Errorf("redactAttr(%v) = %v, want %s=%v", tt.in, got, tt.in.Key, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Package metrics defines the Prometheus metrics of the service and serves
+// them on /metrics
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "curly_succotash"
+
+// Outcomes of generations and AI calls. Generations rejected as invalid,
+// e.g. for bad parameters or a missing game, do not count as failures.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeInvalid = "invalid"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts requests per route, method and status code
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration observes request latency per route
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// Generations counts generation requests per kind and outcome
+	Generations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "generations_total",
+		Help:      "Game generations by kind (game, generate, fork) and outcome (success, error, invalid).",
+	}, []string{"kind", "outcome"})
+
+	// GenerationStepDuration observes the duration of each generation step
+	GenerationStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "generation_step_duration_seconds",
+		Help:      "Duration of generation steps by step and outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"step", "outcome"})
+
+	// LLMCalls counts GenerateContent calls per model and outcome
+	LLMCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_calls_total",
+		Help:      "LLM GenerateContent calls by model and outcome.",
+	}, []string{"model", "outcome"})
+
+	// LLMRetries counts GenerateContent attempts retried after a transient error
+	LLMRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_retries_total",
+		Help:      "LLM GenerateContent attempts retried after a transient error, by model.",
+	}, []string{"model"})
+
+	// LLMTokens counts prompt and output tokens per model
+	LLMTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "LLM tokens by model and kind (prompt, output).",
+	}, []string{"model", "kind"})
+
+	// CardsGenerated counts generated cards per card type
+	CardsGenerated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cards_generated_total",
+		Help:      "Generated cards by card type.",
+	}, []string{"type"})
+
+	// PDFRenderDuration observes PDF render time per engine
+	PDFRenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pdf_render_duration_seconds",
+		Help:      "PDF render time by engine (wkhtmltopdf, gofpdf) and outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"engine", "outcome"})
+
+	// PDFSize observes the size of rendered PDFs per engine
+	PDFSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pdf_size_bytes",
+		Help:      "Size of rendered PDFs by engine.",
+		Buckets:   prometheus.ExponentialBuckets(16<<10, 2, 10),
+	}, []string{"engine"})
+
+	// LimiterRejections counts requests rejected by the rate limiter or the
+	// daily generation quota
+	LimiterRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "limiter_rejections_total",
+		Help:      "Requests rejected by limiter (rate, quota) and route.",
+	}, []string{"limiter", "route"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		Generations,
+		GenerationStepDuration,
+		LLMCalls,
+		LLMRetries,
+		LLMTokens,
+		CardsGenerated,
+		PDFRenderDuration,
+		PDFSize,
+		LimiterRejections,
+	)
+}
+
+// Handler serves the registered metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the connection pool stats of db, such as open, in-use
+// and idle connections and wait counts, labelled with name
+func RegisterDB(name string, db *sql.DB) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Outcome returns the outcome label of err
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// StatusOutcome returns the outcome label of an HTTP status code
+func StatusOutcome(status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return OutcomeError
+	case status >= http.StatusBadRequest:
+		return OutcomeInvalid
+	}
+	return OutcomeSuccess
+}
+
+// ObserveCard counts a generated card of cardType; cards without a type are
+// counted as "untyped"
+func ObserveCard(cardType string) {
+	if cardType == "" {
+		cardType = "untyped"
+	}
+	CardsGenerated.WithLabelValues(cardType).Inc()
+}
+
+// ObserveStep records the duration of a generation step started at start
+func ObserveStep(step string, start time.Time, err error) {
+	GenerationStepDuration.WithLabelValues(step, Outcome(err)).Observe(time.Since(start).Seconds())
+}
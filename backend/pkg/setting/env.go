@@ -0,0 +1,148 @@
+package setting
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables overriding config keys, as in
+// APP_DATABASE_PASSWORD for Database.Password
+const EnvPrefix = "APP_"
+
+// FileSuffix marks an environment variable naming a file that holds the
+// value, e.g. APP_DATABASE_PASSWORD_FILE=/run/secrets/db_password
+const FileSuffix = "_FILE"
+
+// placeholder matches ${NAME} and ${NAME:-default}
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// LookupEnv returns the value of the environment variable name, or the
+// content of the file named by name_FILE without its trailing newline
+func LookupEnv(name string) (string, bool, error) {
+	if v, ok := os.LookupEnv(name); ok {
+		return v, true, nil
+	}
+	path, ok := os.LookupEnv(name + FileSuffix)
+	if !ok {
+		return "", false, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s%s: %s", name, FileSuffix, err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), true, nil
+}
+
+// expandEnv replaces the placeholders of the string values in m. Placeholders
+// of unset variables without a default are kept and reported by their key.
+func expandEnv(m map[string]interface{}, path string) (unresolved []string, err error) {
+	for k, v := range m {
+		key := k
+		if path != "" {
+			key = path + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			u, err := expandEnv(val, key)
+			if err != nil {
+				return nil, err
+			}
+			unresolved = append(unresolved, u...)
+		case []interface{}:
+			for i, item := range val {
+				if s, ok := item.(string); ok {
+					expanded, u, err := expandString(s)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %s", key, err)
+					}
+					val[i] = expanded
+					for _, name := range u {
+						unresolved = append(unresolved, fmt.Sprintf("%s[%d]: ${%s}", key, i, name))
+					}
+				}
+			}
+		case string:
+			expanded, u, err := expandString(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", key, err)
+			}
+			m[k] = expanded
+			for _, name := range u {
+				unresolved = append(unresolved, fmt.Sprintf("%s: ${%s}", key, name))
+			}
+		}
+	}
+	sort.Strings(unresolved)
+	return unresolved, nil
+}
+
+func expandString(s string) (string, []string, error) {
+	var unresolved []string
+	var lookupErr error
+	out := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		parts := placeholder.FindStringSubmatch(match)
+		v, ok, err := LookupEnv(parts[1])
+		if err != nil {
+			lookupErr = err
+		}
+		if ok {
+			return v
+		}
+		if parts[2] != "" {
+			return parts[3]
+		}
+		unresolved = append(unresolved, parts[1])
+		return match
+	})
+	return out, unresolved, lookupErr
+}
+
+// applyEnvOverrides sets config keys from APP_SECTION_KEY variables, or from
+// the file named by APP_SECTION_KEY_FILE. Section and key names are matched
+// without case or underscores in the key, e.g. APP_DATABASE_MAXOPENCONNS or
+// APP_DATABASE_MAX_OPEN_CONNS for Database.MaxOpenConns.
+// Values of list keys are split on commas.
+func applyEnvOverrides(m map[string]interface{}) error {
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, EnvPrefix)
+		if strings.HasSuffix(rest, FileSuffix) {
+			if _, ok := os.LookupEnv(strings.TrimSuffix(name, FileSuffix)); ok {
+				// The plain variable wins over its file
+				continue
+			}
+			rest = strings.TrimSuffix(rest, FileSuffix)
+		}
+		section, key, ok := strings.Cut(strings.ToLower(rest), "_")
+		key = strings.ReplaceAll(key, "_", "")
+		if !ok || section == "" || key == "" {
+			continue
+		}
+		value, _, err := LookupEnv(strings.TrimSuffix(name, FileSuffix))
+		if err != nil {
+			return err
+		}
+
+		sec, ok := m[section].(map[string]interface{})
+		if !ok {
+			sec = make(map[string]interface{})
+			m[section] = sec
+		}
+		if _, isList := sec[key].([]interface{}); isList {
+			items := []interface{}{}
+			for _, item := range strings.Split(value, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+			sec[key] = items
+			continue
+		}
+		sec[key] = value
+	}
+	return nil
+}
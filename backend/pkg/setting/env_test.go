@@ -0,0 +1,163 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("CS_TEST_HOST", "db.internal")
+	t.Setenv("CS_TEST_EMPTY", "")
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CS_TEST_SECRET_FILE", secret)
+
+	tests := []struct {
+		name           string
+		in             interface{}
+		want           interface{}
+		wantUnresolved []string
+	}{
+		{"plain", "localhost", "localhost", nil},
+		{"set", "${CS_TEST_HOST}:3306", "db.internal:3306", nil},
+		{"set but empty", "${CS_TEST_EMPTY:-fallback}", "", nil},
+		{"default", "${CS_TEST_UNSET:-60}", "60", nil},
+		{"empty default", "${CS_TEST_UNSET:-}", "", nil},
+		{"from file", "${CS_TEST_SECRET}", "s3cret", nil},
+		{"unresolved", "${CS_TEST_UNSET}", "${CS_TEST_UNSET}", []string{"section.key: ${CS_TEST_UNSET}"}},
+		{"list", []interface{}{"${CS_TEST_HOST}", "${CS_TEST_UNSET}", 3}, []interface{}{"db.internal", "${CS_TEST_UNSET}", 3}, []string{"section.key[1]: ${CS_TEST_UNSET}"}},
+		{"number", 60, 60, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := map[string]interface{}{"section": map[string]interface{}{"key": tt.in}}
+			unresolved, err := expandEnv(m, "")
+			if err != nil {
+				t.Fatalf("expandEnv: %v", err)
+			}
+			if got := m["section"].(map[string]interface{})["key"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(unresolved, tt.wantUnresolved) {
+				t.Errorf("unresolved = %q, want %q", unresolved, tt.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		section string
+		key     string
+		want    interface{}
+	}{
+		{"override", map[string]string{"APP_DATABASE_HOST": "db"}, "database", "host", "db"},
+		{"underscores in key", map[string]string{"APP_DATABASE_MAX_OPEN_CONNS": "20"}, "database", "maxopenconns", "20"},
+		{"new section", map[string]string{"APP_NEWSECTION_KEY": "v"}, "newsection", "key", "v"},
+		{"list", map[string]string{"APP_APP_ADMINS": "alice, bob"}, "app", "admins", []interface{}{"alice", "bob"}},
+		{"duration", map[string]string{"APP_SERVER_READ_TIMEOUT": "90s"}, "server", "readtimeout", "90s"},
+		{"plain wins over file", map[string]string{"APP_DATABASE_PASSWORD": "plain", "APP_DATABASE_PASSWORD_FILE": "/nonexistent"}, "database", "password", "plain"},
+		{"no key", map[string]string{"APP_DATABASE": "x"}, "database", "host", "localhost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			m := map[string]interface{}{
+				"app":      map[string]interface{}{"admins": []interface{}{}},
+				"database": map[string]interface{}{"host": "localhost", "maxopenconns": 10},
+				"server":   map[string]interface{}{"readtimeout": 60},
+			}
+			if err := applyEnvOverrides(m); err != nil {
+				t.Fatalf("applyEnvOverrides: %v", err)
+			}
+			if got := m[tt.section].(map[string]interface{})[tt.key]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s.%s = %#v, want %#v", tt.section, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_DATABASE_PASSWORD_FILE", path)
+	m := map[string]interface{}{}
+	if err := applyEnvOverrides(m); err != nil {
+		t.Fatalf("applyEnvOverrides: %v", err)
+	}
+	if got := m["database"].(map[string]interface{})["password"]; got != "from-file" {
+		t.Errorf("database.password = %#v, want %q", got, "from-file")
+	}
+
+	t.Setenv("APP_DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err := applyEnvOverrides(map[string]interface{}{}); err == nil {
+		t.Error("applyEnvOverrides with a missing file succeeded")
+	}
+}
+
+func TestDurationOverrides(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "Server:\n  HttpPort: 8080\n  ReadTimeout: 60\n  WriteTimeout: ${CS_TEST_WRITE_TIMEOUT:-30}\nJWT:\n  Secret: s\n  Expire: 7200\n  RefreshExpire: 1.5\n"
+	if err := os.WriteFile(config, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		read  time.Duration
+		write time.Duration
+		jwt   time.Duration
+	}{
+		{"config file", nil, 60 * time.Second, 30 * time.Second, 2 * time.Hour},
+		{"seconds", map[string]string{"APP_SERVER_READ_TIMEOUT": "90", "CS_TEST_WRITE_TIMEOUT": "45"}, 90 * time.Second, 45 * time.Second, 2 * time.Hour},
+		{"units", map[string]string{"APP_SERVER_READTIMEOUT": "90s", "CS_TEST_WRITE_TIMEOUT": "2m", "APP_JWT_EXPIRE": "1h"}, 90 * time.Second, 2 * time.Minute, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			s, err := NewSetting(filepath.Dir(config))
+			if err != nil {
+				t.Fatalf("NewSetting: %v", err)
+			}
+			var server atomic.Pointer[ServerSettingS]
+			if err := s.ReadSection("Server", &server); err != nil {
+				t.Fatalf("ReadSection(Server): %v", err)
+			}
+			var jwt atomic.Pointer[JWTSettingS]
+			if err := s.ReadSection("JWT", &jwt); err != nil {
+				t.Fatalf("ReadSection(JWT): %v", err)
+			}
+			if got := server.Load(); got.ReadTimeout != tt.read || got.WriteTimeout != tt.write {
+				t.Errorf("timeouts = %v, %v, want %v, %v", got.ReadTimeout, got.WriteTimeout, tt.read, tt.write)
+			}
+			if got := jwt.Load(); got.Expire != tt.jwt || got.RefreshExpire != 1500*time.Millisecond {
+				t.Errorf("JWT expiries = %v, %v, want %v, 1.5s", got.Expire, got.RefreshExpire, tt.jwt)
+			}
+		})
+	}
+
+	t.Setenv("APP_SERVER_READ_TIMEOUT", "soon")
+	s, err := NewSetting(filepath.Dir(config))
+	if err != nil {
+		t.Fatalf("NewSetting: %v", err)
+	}
+	var server atomic.Pointer[ServerSettingS]
+	if err := s.ReadSection("Server", &server); err == nil {
+		t.Errorf("ReadSection accepted ReadTimeout %q", "soon")
+	}
+}
@@ -0,0 +1,62 @@
+package setting
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the value of secret fields
+const Redacted = "[REDACTED]"
+
+// secretFields are suffixes of field names holding credentials
+var secretFields = []string{"Password", "Secret", "Key", "Token"}
+
+// RedactedSections returns the sections read so far, keyed by section name,
+// with the values of secret fields replaced by Redacted
+func RedactedSections() map[string]interface{} {
+	out := make(map[string]interface{}, len(sections))
+	for k, sec := range sections {
+		out[k] = redact(sec.current())
+	}
+	return out
+}
+
+// redact converts structs to maps, blanking non-empty secret fields
+func redact(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if v.Kind() != reflect.Struct {
+		return v.Interface()
+	}
+
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if isSecret(field.Name) && !v.Field(i).IsZero() {
+			out[field.Name] = Redacted
+			continue
+		}
+		out[field.Name] = redact(v.Field(i))
+	}
+	return out
+}
+
+func isSecret(name string) bool {
+	for _, s := range secretFields {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"strings"
 	"time"
 )
 
@@ -9,6 +10,10 @@ type AppSettingS struct {
 	LogSavePath string
 	LogFileName string
 	LogFileExt  string
+	LogLevel    string
+	LogPackages map[string]string
+	LogConsole  string
+	Admins      []string
 }
 
 type DatabaseSettingS struct {
@@ -30,10 +35,22 @@ type ServerSettingS struct {
 	HttpPort     string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// TrustedProxies are the IPs or CIDRs of reverse proxies whose
+	// X-Forwarded-For header gives the client IP. Empty trusts none.
+	TrustedProxies []string
 }
 
 type StoragePathSettingS struct {
-	PDFFoldar string
+	PDFFoldar   string
+	FontFolder  string
+	ThemeFolder string
+}
+
+type FontSettingS struct {
+	Latin    string
+	Han      string
+	Japanese string
+	Emoji    string
 }
 
 type AISettingS struct {
@@ -43,31 +60,40 @@ type AISettingS struct {
 	TopP            float32
 	TopK            float32
 	MaxOutputTokens int32
+	MaxRetries      int
 	Stream          bool
+	Prices          map[string]ModelPrice
 }
 
-var sections = make(map[string]interface{})
-
-func (s *Setting) ReadSection(k string, v interface{}) error {
-	err := s.vp.UnmarshalKey(k, v)
-	if err != nil {
-		return err
-	}
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Prompt float64
+	Output float64
+}
 
-	if _, ok := sections[k]; !ok {
-		sections[k] = v
-	}
+// Price returns the price of model, matched without case
+func (s *AISettingS) Price(model string) (ModelPrice, bool) {
+	price, ok := s.Prices[strings.ToLower(model)]
+	return price, ok
+}
 
-	return nil
+type JWTSettingS struct {
+	Secret        string
+	Issuer        string
+	Expire        time.Duration
+	RefreshExpire time.Duration
 }
 
-func (s *Setting) ReloadAllSection() error {
-	for k, v := range sections {
-		err := s.ReadSection(k, v)
-		if err != nil {
-			return err
-		}
-	}
+type LimiterSettingS struct {
+	Store            string
+	DailyGenerations int64
+}
 
-	return nil
+type TracingSettingS struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	FilePath    string
+	ServiceName string
+	SampleRatio float64
 }
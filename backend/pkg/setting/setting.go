@@ -1,14 +1,78 @@
 package setting
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/fsnotify/fsnotify"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
 type Setting struct {
 	vp *viper.Viper
+
+	// reloadMu serializes reloads, including their notifications. mu guards
+	// the fields below and is released before subscribers run, so they may
+	// call Unresolved.
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+	subscribers []subscriber
+	onError     func(error)
+	unresolved  []string
+	overrides   map[string]interface{}
+}
+
+// Change is a configuration value changed by a reload. Secret values are
+// redacted.
+type Change struct {
+	Section string
+	Key     string
+	Old     interface{}
+	New     interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s.%s: %v -> %v", c.Section, c.Key, c.Old, c.New)
+}
+
+// subscriber is notified of reloads changing any of its sections, or any
+// section when sections is empty
+type subscriber struct {
+	sections []string
+	fn       func(changes []Change)
+}
+
+// section accesses the atomic.Pointer a config section is read into
+type section struct {
+	typ   reflect.Type
+	load  reflect.Value
+	store reflect.Value
+}
+
+// sectionOf returns the section of v, which must be an *atomic.Pointer
+func sectionOf(k string, v interface{}) (section, error) {
+	rv := reflect.ValueOf(v)
+	load, store := rv.MethodByName("Load"), rv.MethodByName("Store")
+	if !load.IsValid() || !store.IsValid() || load.Type().NumOut() != 1 || load.Type().Out(0).Kind() != reflect.Pointer {
+		return section{}, fmt.Errorf("section %s must be read into an atomic.Pointer, got %T", k, v)
+	}
+	return section{typ: load.Type().Out(0).Elem(), load: load, store: store}, nil
+}
+
+// current returns the section pointer currently stored
+func (sec section) current() reflect.Value {
+	return sec.load.Call(nil)[0]
 }
 
+// sections holds the sections passed to ReadSection, keyed by name
+var sections = make(map[string]section)
+
 func NewSetting(configs ...string) (*Setting, error) {
 	vp := viper.New()
 	vp.SetConfigName("config")
@@ -23,16 +87,267 @@ func NewSetting(configs ...string) (*Setting, error) {
 		return nil, err
 	}
 
-	s := &Setting{vp}
+	s := &Setting{vp: vp}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
 	s.WatchSettingChange()
 	return s, nil
 }
 
+// load expands the ${NAME} and ${NAME:-default} placeholders of the config
+// file and applies APP_SECTION_KEY environment overrides on top of it
+func (s *Setting) load() error {
+	raw := viper.New()
+	raw.SetConfigFile(s.vp.ConfigFileUsed())
+	raw.SetConfigType("yaml")
+	if err := raw.ReadInConfig(); err != nil {
+		return err
+	}
+	m := raw.AllSettings()
+	unresolved, err := expandEnv(m, "")
+	if err != nil {
+		return err
+	}
+	if err := applyEnvOverrides(m); err != nil {
+		return err
+	}
+	for key, value := range s.overrides {
+		name, field, _ := strings.Cut(key, ".")
+		sec, ok := m[name].(map[string]interface{})
+		if !ok {
+			sec = make(map[string]interface{})
+			m[name] = sec
+		}
+		sec[field] = value
+	}
+	if err := s.vp.MergeConfigMap(m); err != nil {
+		return err
+	}
+	s.unresolved = unresolved
+	return nil
+}
+
+// Override sets key, given as "Section.Key", over the config file and the
+// environment, for example from a command line flag. It applies to sections
+// read afterwards and to reloads.
+func (s *Setting) Override(key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.overrides == nil {
+		s.overrides = make(map[string]interface{})
+	}
+	s.overrides[strings.ToLower(key)] = value
+	return s.load()
+}
+
+// Unresolved lists the placeholders left in the config because their
+// environment variable is not set and they have no default, as
+// "Section.Key: ${NAME}"
+func (s *Setting) Unresolved() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unresolved
+}
+
 func (s *Setting) WatchSettingChange() {
 	go func() {
 		s.vp.WatchConfig()
 		s.vp.OnConfigChange(func(in fsnotify.Event) {
-			_ = s.ReloadAllSection()
+			if err := s.ReloadAllSection(); err != nil {
+				s.mu.Lock()
+				onError := s.onError
+				s.mu.Unlock()
+				if onError != nil {
+					onError(err)
+				}
+			}
 		})
 	}()
 }
+
+// Subscribe registers fn to run after a reload changes any of the named
+// sections, or any section when none are named. fn receives the changes of
+// every section, after the new values are in place.
+func (s *Setting) Subscribe(fn func(changes []Change), sections ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, subscriber{sections: sections, fn: fn})
+}
+
+// OnReloadError registers fn to receive errors of reloads triggered by
+// config file changes. The previous configuration stays in place.
+func (s *Setting) OnReloadError(fn func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onError = fn
+}
+
+// ReadSection reads section k into v, an *atomic.Pointer to the section
+// struct (e.g. &global.AppSetting). Reloads store new section values into it,
+// so readers never see a partially updated section. The section is
+// validated.
+func (s *Setting) ReadSection(k string, v interface{}) error {
+	sec, err := sectionOf(k, v)
+	if err != nil {
+		return err
+	}
+	value := reflect.New(sec.typ)
+	if err := s.unmarshal(k, value.Interface()); err != nil {
+		return err
+	}
+	if err := prepare(k, value.Interface()); err != nil {
+		return err
+	}
+	sec.store.Call([]reflect.Value{value})
+
+	if _, ok := sections[k]; !ok {
+		sections[k] = sec
+	}
+
+	return nil
+}
+
+// ReloadAllSection reads every section into new values, validates them and
+// only then replaces the current sections and notifies subscribers. A
+// section failing validation rejects the whole reload.
+func (s *Setting) ReloadAllSection() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	next, changes, subscribers, err := s.reload()
+	if err != nil {
+		return err
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	for _, sub := range subscribers {
+		if sub.interested(next) {
+			sub.fn(changes)
+		}
+	}
+	return nil
+}
+
+// reload stores the changed sections and returns them, their changes and
+// the subscribers to notify
+func (s *Setting) reload() (map[string]reflect.Value, []Change, []subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config: %s", err)
+	}
+	next := make(map[string]reflect.Value, len(sections))
+	var changes []Change
+	for k, sec := range sections {
+		current := sec.current()
+		candidate := reflect.New(sec.typ)
+		if err := s.unmarshal(k, candidate.Interface()); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read section %s: %s", k, err)
+		}
+		if err := prepare(k, candidate.Interface()); err != nil {
+			return nil, nil, nil, err
+		}
+		if !current.IsNil() && reflect.DeepEqual(current.Elem().Interface(), candidate.Elem().Interface()) {
+			continue
+		}
+		next[k] = candidate
+		changes = append(changes, diff(k, current, candidate)...)
+	}
+
+	for k, candidate := range next {
+		sections[k].store.Call([]reflect.Value{candidate})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return next, changes, s.subscribers, nil
+}
+
+// unmarshal reads section k into v, decoding durations with durationHook
+func (s *Setting) unmarshal(k string, v interface{}) error {
+	return s.vp.UnmarshalKey(k, v, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		durationHook,
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+}
+
+// durationHook decodes durations given as a number of seconds, as in the
+// config file, or as a string with a unit such as "90s" or "2h". Numbers in
+// strings, as set by environment variables, are seconds too.
+func durationHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	v := reflect.ValueOf(data)
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Duration(v.Int()) * time.Second, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Duration(v.Uint()) * time.Second, nil
+	case reflect.Float32, reflect.Float64:
+		return time.Duration(v.Float() * float64(time.Second)), nil
+	case reflect.String:
+		str := strings.TrimSpace(v.String())
+		if seconds, err := strconv.ParseFloat(str, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: give seconds or a duration such as \"90s\"", str)
+		}
+		return d, nil
+	}
+	return data, nil
+}
+
+func (sub subscriber) interested(changed map[string]reflect.Value) bool {
+	if len(sub.sections) == 0 {
+		return true
+	}
+	for _, k := range sub.sections {
+		if _, ok := changed[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare validates a section read from the config
+func prepare(k string, v interface{}) error {
+	if val, ok := v.(validator); ok {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("invalid %s section: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// diff lists the redacted fields differing between two values of a section
+func diff(section string, old, new reflect.Value) []Change {
+	oldFields, _ := redact(old).(map[string]interface{})
+	newFields, _ := redact(new).(map[string]interface{})
+	var changes []Change
+	for key, n := range newFields {
+		o := oldFields[key]
+		if reflect.DeepEqual(o, n) && (n != Redacted || sameField(old, new, key)) {
+			continue
+		}
+		changes = append(changes, Change{Section: section, Key: key, Old: o, New: n})
+	}
+	return changes
+}
+
+// sameField reports whether a field holds the same value in two section
+// pointers, for redacted fields whose redacted values always match
+func sameField(old, new reflect.Value, key string) bool {
+	if old.IsNil() || new.IsNil() {
+		return false
+	}
+	return reflect.DeepEqual(old.Elem().FieldByName(key).Interface(), new.Elem().FieldByName(key).Interface())
+}
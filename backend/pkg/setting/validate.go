@@ -0,0 +1,143 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+)
+
+// validator is implemented by sections that check their values. Sections
+// failing validation are rejected at startup and on reload.
+type validator interface {
+	Validate() error
+}
+
+// LogLevels lists the accepted values of App.LogLevel and App.LogPackages
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// LogConsoles lists the accepted values of App.LogConsole
+var LogConsoles = []string{"pretty", "json", "none"}
+
+// RunModes lists the accepted values of App.RunMode, which are the gin modes
+var RunModes = []string{"debug", "release", "test"}
+
+func (s *AppSettingS) Validate() error {
+	if !slices.Contains(RunModes, s.RunMode) {
+		return fmt.Errorf("RunMode must be one of %v, got %q", RunModes, s.RunMode)
+	}
+	if s.LogLevel != "" && !slices.Contains(LogLevels, s.LogLevel) {
+		return fmt.Errorf("LogLevel must be one of %v, got %q", LogLevels, s.LogLevel)
+	}
+	if s.LogConsole != "" && !slices.Contains(LogConsoles, s.LogConsole) {
+		return fmt.Errorf("LogConsole must be one of %v, got %q", LogConsoles, s.LogConsole)
+	}
+	for pkg, level := range s.LogPackages {
+		if !slices.Contains(LogLevels, level) {
+			return fmt.Errorf("LogPackages.%s must be one of %v, got %q", pkg, LogLevels, level)
+		}
+	}
+	return nil
+}
+
+func (s *DatabaseSettingS) Validate() error {
+	if s.MaxIdleConns < 0 || s.MaxOpenConns < 0 {
+		return errors.New("MaxIdleConns and MaxOpenConns must not be negative")
+	}
+	if s.MaxOpenConns > 0 && s.MaxIdleConns > s.MaxOpenConns {
+		return fmt.Errorf("MaxIdleConns (%d) must not exceed MaxOpenConns (%d)", s.MaxIdleConns, s.MaxOpenConns)
+	}
+	return nil
+}
+
+func (s *ServerSettingS) Validate() error {
+	port, err := strconv.Atoi(s.HttpPort)
+	if err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("HttpPort must be a port number, got %q", s.HttpPort)
+	}
+	if s.ReadTimeout <= 0 || s.WriteTimeout <= 0 {
+		return errors.New("ReadTimeout and WriteTimeout must be positive")
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("TrustedProxies must hold IPs or CIDRs, got %q", proxy)
+		}
+	}
+	return nil
+}
+
+func (s *AISettingS) Validate() error {
+	if s.Model == "" {
+		return errors.New("Model is required")
+	}
+	if s.APIKey == "" {
+		return errors.New("APIKey must name the environment variable holding the API key")
+	}
+	if s.Temperature < 0 || s.Temperature > 2 {
+		return fmt.Errorf("Temperature must be between 0 and 2, got %v", s.Temperature)
+	}
+	if s.TopP < 0 || s.TopP > 1 {
+		return fmt.Errorf("TopP must be between 0 and 1, got %v", s.TopP)
+	}
+	if s.MaxOutputTokens <= 0 {
+		return errors.New("MaxOutputTokens must be positive")
+	}
+	if s.MaxRetries < 0 {
+		return errors.New("MaxRetries must not be negative")
+	}
+	for model, price := range s.Prices {
+		if price.Prompt < 0 || price.Output < 0 {
+			return fmt.Errorf("Prices.%s must not be negative", model)
+		}
+	}
+	return nil
+}
+
+// DefaultJWTSecret is the JWT secret shipped in config.yaml, only accepted
+// in the debug run mode
+const DefaultJWTSecret = "curly-succotash"
+
+// CheckSecret rejects the shipped secret outside the debug run mode, as
+// anyone could sign tokens with it
+func (s *JWTSettingS) CheckSecret(runMode string) error {
+	if s.Secret == DefaultJWTSecret && runMode != "debug" {
+		return fmt.Errorf("JWT.Secret must be changed from the shipped default in %s mode", runMode)
+	}
+	return nil
+}
+
+func (s *JWTSettingS) Validate() error {
+	if s.Secret == "" {
+		return errors.New("Secret is required")
+	}
+	if s.Expire <= 0 || s.RefreshExpire <= 0 {
+		return errors.New("Expire and RefreshExpire must be positive")
+	}
+	return nil
+}
+
+func (s *LimiterSettingS) Validate() error {
+	if s.Store != "memory" && s.Store != "sql" {
+		return fmt.Errorf("Store must be memory or sql, got %q", s.Store)
+	}
+	if s.DailyGenerations < 0 {
+		return errors.New("DailyGenerations must not be negative")
+	}
+	return nil
+}
+
+func (s *TracingSettingS) Validate() error {
+	switch s.Exporter {
+	case "", "none", "stdout", "file", "otlp":
+	default:
+		return fmt.Errorf("Exporter must be none, stdout, file or otlp, got %q", s.Exporter)
+	}
+	if s.Exporter == "file" && s.FilePath == "" {
+		return errors.New("FilePath is required by the file exporter")
+	}
+	if s.SampleRatio < 0 || s.SampleRatio > 1 {
+		return fmt.Errorf("SampleRatio must be between 0 and 1, got %v", s.SampleRatio)
+	}
+	return nil
+}
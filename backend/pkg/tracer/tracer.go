@@ -0,0 +1,62 @@
+// Package tracer sets up OpenTelemetry tracing and exposes the trace of a
+// request to the logger and response writers
+package tracer
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceparentHeader is the W3C Trace Context request and response header
+	TraceparentHeader = "traceparent"
+	// TraceIDHeader echoes the trace ID in responses
+	TraceIDHeader = "X-Trace-ID"
+
+	// ContextTraceID is the gin context key holding the request's trace ID
+	ContextTraceID = "X-Trace-ID"
+	// ContextSpanID is the gin context key holding the request's span ID
+	ContextSpanID = "X-Span-ID"
+)
+
+// instrumentationName names the tracer creating this service's own spans
+const instrumentationName = "curly-succotash/backend"
+
+// TraceContext identifies the current span within a distributed trace
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// FromContext returns the trace of the current span in ctx
+func FromContext(ctx context.Context) (TraceContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceID: sc.TraceID().String(), SpanID: sc.SpanID().String()}, true
+}
+
+// Inject writes the traceparent of the current span in ctx to header
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Start starts a span as a child of the span in ctx. Callers must end it.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records err on span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
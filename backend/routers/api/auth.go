@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRequest defines the request payload for creating a user
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=64"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// LoginRequest defines the request payload for signing in
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest defines the request payload for renewing tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Register creates a user account.
+//
+// @Summary      Register a user
+// @Description  Creates a user account with a bcrypt-hashed password (at least 8 characters). The username "system" is reserved.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      RegisterRequest  true  "Account"
+// @Success      200   {object}  map[string]interface{}  "User registered successfully"
+// @Failure      400   {object}  app.ErrorResponse       "Bad request"
+// @Failure      409   {object}  app.ErrorResponse       "username already taken"
+// @Failure      500   {object}  app.ErrorResponse       "Internal server error"
+// @Router       /auth/register [post]
+func Register(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	user, err := service.Register(ctx, req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrUserExists) {
+			return errcode.UserExists
+		}
+		if errors.Is(err, service.ErrReservedUsername) {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+		global.Logger.Errorf(ctx, "failed to register user: %s", err)
+		return errcode.ErrorRegisterFail
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"message":  "User registered successfully",
+	})
+	return nil
+}
+
+// Login signs a user in.
+//
+// @Summary      Sign in
+// @Description  Checks the username and password and returns a JWT access token and a longer-lived refresh token.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      LoginRequest  true  "Credentials"
+// @Success      200   {object}  service.TokenPair
+// @Failure      400   {object}  app.ErrorResponse  "Bad request"
+// @Failure      401   {object}  app.ErrorResponse  "invalid username or password"
+// @Failure      500   {object}  app.ErrorResponse  "Internal server error"
+// @Router       /auth/login [post]
+func Login(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	tokens, err := service.Login(ctx, req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			return errcode.InvalidCredentials
+		}
+		global.Logger.Errorf(ctx, "failed to sign in: %s", err)
+		return errcode.UnauthorizedTokenGenerate
+	}
+	c.JSON(http.StatusOK, tokens)
+	return nil
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+//
+// @Summary      Refresh tokens
+// @Description  Issues a new access and refresh token for a valid refresh token.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      RefreshRequest  true  "Refresh token"
+// @Success      200   {object}  service.TokenPair
+// @Failure      400   {object}  app.ErrorResponse  "Bad request"
+// @Failure      401   {object}  app.ErrorResponse  "invalid or expired refresh token"
+// @Failure      500   {object}  app.ErrorResponse  "Internal server error"
+// @Router       /auth/refresh [post]
+func Refresh(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	tokens, err := service.RefreshTokens(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) || errors.Is(err, service.ErrInvalidCredentials) {
+			return errcode.UnauthorizedTokenError
+		}
+		global.Logger.Errorf(ctx, "failed to refresh tokens: %s", err)
+		return errcode.UnauthorizedTokenGenerate
+	}
+	c.JSON(http.StatusOK, tokens)
+	return nil
+}
+
+// AuthRequest defines the AppKey credentials exchanged for a token
+type AuthRequest struct {
+	AppKey    string `form:"app_key" json:"app_key" binding:"required"`
+	AppSecret string `form:"app_secret" json:"app_secret" binding:"required"`
+}
+
+// GetAuth issues an access token for an AppKey/AppSecret pair.
+//
+// @Summary      Get an AppKey token
+// @Description  Exchanges an AppKey and AppSecret (JSON or form) for an access token limited to the key's scopes. There is no refresh token; request a new token when it expires.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      AuthRequest  true  "AppKey credentials"
+// @Success      200   {object}  service.AppToken
+// @Failure      400   {object}  app.ErrorResponse  "Bad request"
+// @Failure      401   {object}  app.ErrorResponse       "AppKey and AppSecret not found"
+// @Failure      500   {object}  app.ErrorResponse       "Internal server error"
+// @Router       /auth [post]
+func GetAuth(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	var req AuthRequest
+	if err := c.ShouldBind(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	token, err := service.IssueAppToken(ctx, req.AppKey, req.AppSecret)
+	if err != nil {
+		if errors.Is(err, service.ErrAppKeyNotFound) {
+			return errcode.UnauthorizedAuthNotExist
+		}
+		global.Logger.Errorf(ctx, "failed to issue app token: %s", err)
+		return errcode.UnauthorizedTokenGenerate
+	}
+	c.JSON(http.StatusOK, token)
+	return nil
+}
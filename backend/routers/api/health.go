@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startedAt is when the server process started
+var startedAt = time.Now()
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Module    string            `json:"module"`
+	Version   string            `json:"version"`
+	VCS       map[string]string `json:"vcs,omitempty"`
+}
+
+// DebugResponse is the body of the admin debug endpoint
+type DebugResponse struct {
+	Build      BuildInfo              `json:"build"`
+	StartedAt  time.Time              `json:"started_at"`
+	Uptime     string                 `json:"uptime"`
+	Goroutines int                    `json:"goroutines"`
+	Readiness  service.Readiness      `json:"readiness"`
+	Config     map[string]interface{} `json:"config"`
+}
+
+// Healthz reports that the process is up, for liveness probes.
+//
+// @Summary      Liveness probe
+// @Description  Returns 200 as long as the server can handle HTTP requests. It does not check dependencies.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "ok"
+// @Router       /healthz [get]
+func Healthz(c *gin.Context) *errcode.Error {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	return nil
+}
+
+// Readyz reports whether the server can handle requests, for readiness
+// probes. It only returns the status of each check, the errors are shown by
+// Debug.
+//
+// @Summary      Readiness probe
+// @Description  Checks the database connection, migration status, PDF folder, wkhtmltopdf and AI provider configuration. Returns 503 when any check fails. Results are cached for a few seconds; the errors of failed checks are only returned by /admin/debug.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  service.Readiness  "ready"
+// @Failure      503  {object}  service.Readiness  "not ready"
+// @Failure      429  {object}  app.ErrorResponse  "too many requests"
+// @Router       /readyz [get]
+func Readyz(c *gin.Context) *errcode.Error {
+	readiness := service.CachedReadiness(c.Request.Context()).Statuses()
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, readiness)
+	return nil
+}
+
+// Debug returns build information, the readiness checks with their errors
+// and the loaded configuration with secrets redacted.
+//
+// @Summary      Diagnostics
+// @Description  Returns build info, uptime, the result of the readiness checks with their errors and the loaded configuration with passwords, secrets and keys redacted. Requires an admin user listed in App.Admins.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  DebugResponse
+// @Failure      401  {object}  app.ErrorResponse  "unauthorized"
+// @Failure      403  {object}  app.ErrorResponse  "not an admin"
+// @Router       /admin/debug [get]
+func Debug(c *gin.Context) *errcode.Error {
+	c.JSON(http.StatusOK, DebugResponse{
+		Build:      buildInfo(),
+		StartedAt:  startedAt,
+		Uptime:     time.Since(startedAt).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		Readiness:  service.CheckReadiness(c.Request.Context()),
+		Config:     setting.RedactedSections(),
+	})
+	return nil
+}
+
+// buildInfo reads the module and VCS information embedded by the Go toolchain
+func buildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module, info.Version = bi.Main.Path, bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs", "vcs.revision", "vcs.time", "vcs.modified":
+			if info.VCS == nil {
+				info.VCS = map[string]string{}
+			}
+			info.VCS[s.Key] = s.Value
+		}
+	}
+	return info
+}
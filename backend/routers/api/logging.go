@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/logger"
+	"curly-succotash/backend/pkg/setting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogLevels is the minimum log level and the per-package overrides
+type LogLevels struct {
+	Level    string            `json:"level" example:"info"`
+	Packages map[string]string `json:"packages" example:"internal/model:warn"`
+}
+
+// GetLogLevels returns the log levels in effect.
+//
+// @Summary      Get log levels
+// @Description  Returns the minimum log level and the per-package levels in effect. Requires an admin user listed in App.Admins.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  LogLevels
+// @Failure      401  {object}  app.ErrorResponse  "unauthorized"
+// @Failure      403  {object}  app.ErrorResponse  "not an admin"
+// @Router       /admin/log-level [get]
+func GetLogLevels(c *gin.Context) *errcode.Error {
+	c.JSON(http.StatusOK, logLevels(global.Logger.Levels()))
+	return nil
+}
+
+// SetLogLevels changes the log levels of the running server.
+//
+// @Summary      Set log levels
+// @Description  Changes the minimum log level and, when packages is given, replaces the per-package levels. Levels are debug, info, warn or error. Changes last until the App config section is reloaded or the server restarts. Requires an admin user listed in App.Admins.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        levels  body      LogLevels  true  "Log levels; an empty level keeps the current one"
+// @Success      200     {object}  LogLevels
+// @Failure      400     {object}  app.ErrorResponse  "unknown level"
+// @Failure      401     {object}  app.ErrorResponse  "unauthorized"
+// @Failure      403     {object}  app.ErrorResponse  "not an admin"
+// @Router       /admin/log-level [put]
+func SetLogLevels(c *gin.Context) *errcode.Error {
+	var req LogLevels
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	levels := global.Logger.Levels()
+	var err error
+	if req.Level != "" {
+		if levels.Default, err = parseLogLevel(req.Level); err != nil {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+	}
+	if req.Packages != nil {
+		levels.Packages = make(map[string]logger.Level, len(req.Packages))
+		for pkg, level := range req.Packages {
+			if levels.Packages[pkg], err = parseLogLevel(level); err != nil {
+				return errcode.InvalidParams.WithDetails(pkg + ": " + err.Error())
+			}
+		}
+	}
+	// Logged before applying, so that raising the level still records it
+	res := logLevels(levels)
+	global.Logger.Infof(c.Request.Context(), "%s changed log levels to %s %v", app.Actor(c.Request.Context()), res.Level, res.Packages)
+	global.Logger.SetLevels(levels)
+
+	c.JSON(http.StatusOK, res)
+	return nil
+}
+
+// parseLogLevel accepts the levels allowed in App.LogLevel
+func parseLogLevel(s string) (logger.Level, error) {
+	if !slices.Contains(setting.LogLevels, s) {
+		return logger.LevelDebug, fmt.Errorf("unknown log level %q, want one of %v", s, setting.LogLevels)
+	}
+	return logger.ParseLevel(s)
+}
+
+func logLevels(levels logger.Levels) LogLevels {
+	res := LogLevels{Level: levels.Default.String(), Packages: make(map[string]string, len(levels.Packages))}
+	for pkg, level := range levels.Packages {
+		res.Packages[pkg] = level.String()
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageQuery filters and groups the token usage of every user
+type UsageQuery struct {
+	Group  string `form:"group" binding:"omitempty,oneof=game user day model"`
+	User   string `form:"user"`
+	GameID uint32 `form:"game_id"`
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// ListUsage returns the AI token usage and cost of every user.
+//
+// @Summary      Token usage of all users
+// @Description  Returns the AI tokens and cost in USD of all requests, grouped by user (default), day, game or model. Requires an admin user listed in App.Admins.
+// @Tags         admin
+// @Produce      json
+// @Param        group    query     string  false  "Grouping: user, day, game or model"
+// @Param        user     query     string  false  "Username"
+// @Param        game_id  query     int     false  "Game ID"
+// @Param        from     query     string  false  "First UTC day, YYYY-MM-DD"
+// @Param        to       query     string  false  "Last UTC day, YYYY-MM-DD"
+// @Success      200      {object}  service.UsageReport
+// @Failure      400      {object}  app.ErrorResponse  "invalid filter"
+// @Failure      401      {object}  app.ErrorResponse  "unauthorized"
+// @Failure      403      {object}  app.ErrorResponse  "not an admin"
+// @Failure      500      {object}  app.ErrorResponse  "internal server error"
+// @Router       /admin/usage [get]
+func ListUsage(c *gin.Context) *errcode.Error {
+	var q UsageQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+	if q.Group == "" {
+		q.Group = service.UsageByUser
+	}
+	filter := service.UsageFilter{Actor: q.User, GameID: q.GameID, From: q.From, To: q.To}
+	report, err := service.UsageTotals(c.Request.Context(), filter, q.Group)
+	if err != nil {
+		global.Logger.Errorf(c.Request.Context(), "%s: %s", errcode.ErrorGetUsageFail.Msg(), err)
+		return errcode.ErrorGetUsageFail
+	}
+	c.JSON(http.StatusOK, report)
+	return nil
+}
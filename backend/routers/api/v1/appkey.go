@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAppKeyRequest defines the request payload for creating an AppKey
+type CreateAppKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=128"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// AppKeyResponse is an AppKey with its AppSecret, returned only when the
+// secret is created or rotated
+type AppKeyResponse struct {
+	model.Auth
+	AppSecret string `json:"app_secret"`
+}
+
+// CreateAppKey creates an AppKey/AppSecret pair for service-to-service access.
+//
+// @Summary      Create an AppKey
+// @Description  Creates an AppKey owned by the signed-in user with the given scopes (games:read, games:write, games:generate, games:export). The AppSecret is returned only once.
+// @Tags         appkeys
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CreateAppKeyRequest  true  "AppKey"
+// @Success      200   {object}  AppKeyResponse
+// @Failure      400   {object}  app.ErrorResponse  "invalid scope"
+// @Failure      500   {object}  app.ErrorResponse  "Internal server error"
+// @Router       /api/v1/appkeys [post]
+func CreateAppKey(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	var req CreateAppKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	auth, secret, err := service.CreateAppKey(ctx, req.Name, req.Scopes)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidScope) {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+		return serverError(c, errcode.ErrorCreateAppKeyFail, err)
+	}
+	c.JSON(http.StatusOK, AppKeyResponse{Auth: auth, AppSecret: secret})
+	return nil
+}
+
+// ListAppKeys lists the AppKeys of the signed-in user.
+//
+// @Summary      List AppKeys
+// @Description  Lists the active AppKeys owned by the signed-in user. Secrets are never returned.
+// @Tags         appkeys
+// @Produce      json
+// @Success      200  {array}   model.Auth
+// @Failure      500  {object}  app.ErrorResponse  "Internal server error"
+// @Router       /api/v1/appkeys [get]
+func ListAppKeys(c *gin.Context) *errcode.Error {
+	auths, err := service.ListAppKeys(c.Request.Context())
+	if err != nil {
+		return serverError(c, errcode.ErrorListAppKeysFail, err)
+	}
+	c.JSON(http.StatusOK, auths)
+	return nil
+}
+
+// RotateAppKey replaces the AppSecret of an AppKey.
+//
+// @Summary      Rotate an AppKey
+// @Description  Issues a new AppSecret for an AppKey of the signed-in user. The old secret and every token issued with it stop working.
+// @Tags         appkeys
+// @Produce      json
+// @Param        id   path      int  true  "AppKey ID"
+// @Success      200  {object}  AppKeyResponse
+// @Failure      404  {object}  app.ErrorResponse  "app key not found"
+// @Failure      500  {object}  app.ErrorResponse  "Internal server error"
+// @Router       /api/v1/appkeys/{id}/rotate [post]
+func RotateAppKey(c *gin.Context) *errcode.Error {
+	id, cerr := parseID("app key ID", c.Param("id"))
+	if cerr != nil {
+		return cerr
+	}
+
+	auth, secret, err := service.RotateAppKey(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrAppKeyNotFound) {
+			return errcode.AppKeyNotFound
+		}
+		return serverError(c, errcode.ErrorRotateAppKeyFail, err)
+	}
+	c.JSON(http.StatusOK, AppKeyResponse{Auth: auth, AppSecret: secret})
+	return nil
+}
+
+// RevokeAppKey disables an AppKey.
+//
+// @Summary      Revoke an AppKey
+// @Description  Disables an AppKey of the signed-in user together with every token issued for it.
+// @Tags         appkeys
+// @Produce      json
+// @Param        id   path      int  true  "AppKey ID"
+// @Success      200  {object}  map[string]string  "App key revoked successfully"
+// @Failure      404  {object}  app.ErrorResponse  "app key not found"
+// @Failure      500  {object}  app.ErrorResponse  "Internal server error"
+// @Router       /api/v1/appkeys/{id} [delete]
+func RevokeAppKey(c *gin.Context) *errcode.Error {
+	id, cerr := parseID("app key ID", c.Param("id"))
+	if cerr != nil {
+		return cerr
+	}
+
+	if err := service.RevokeAppKey(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrAppKeyNotFound) {
+			return errcode.AppKeyNotFound
+		}
+		return serverError(c, errcode.ErrorRevokeAppKeyFail, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "App key revoked successfully"})
+	return nil
+}
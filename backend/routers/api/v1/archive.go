@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"curly-succotash/backend/internal/archive"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds the size of an uploaded game bundle
+const maxImportSize = archive.MaxSize
+
+// ExportGame exports a game as a portable, versioned bundle.
+//
+// @Summary      Export a game bundle
+// @Description  Exports a game with its cards, meta values and provenance as a versioned JSON bundle, or as a zip archive containing game.json.
+// @Tags         games
+// @Produce      json
+// @Produce      application/zip
+// @Param        id      path   string  true   "Game ID"
+// @Param        format  query  string  false  "json (default) or zip"
+// @Success      200  {object}  archive.Bundle
+// @Failure      400  {object}  app.ErrorResponse  "unsupported format"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/export [get]
+func ExportGame(c *gin.Context) *errcode.Error {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		return invalidParams("unsupported format: %s", format)
+	}
+
+	bundle, err := service.ExportGame(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorExportGameFail, err)
+	}
+
+	var buf bytes.Buffer
+	if format == "zip" {
+		if err := bundle.WriteZip(&buf); err != nil {
+			return serverError(c, errcode.ErrorExportGameFail, err)
+		}
+	}
+
+	filename := fmt.Sprintf("game_%d.%s", bundle.Provenance.SourceGameID, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "json" {
+		c.JSON(http.StatusOK, bundle)
+		return nil
+	}
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	return nil
+}
+
+// ImportGame recreates a game from a bundle produced by ExportGame.
+//
+// @Summary      Import a game bundle
+// @Description  Validates the schema version of a game bundle (JSON body, zip body, or multipart "file" upload) and recreates the game, its cards and meta values with new IDs.
+// @Tags         games
+// @Accept       json
+// @Accept       application/zip
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        body  body      archive.Bundle  true  "Game bundle"
+// @Success      200   {object}  map[string]interface{}  "Game imported successfully"
+// @Failure      400   {object}  app.ErrorResponse       "invalid bundle or unsupported schema version"
+// @Failure      500   {object}  app.ErrorResponse       "internal server error"
+// @Router       /api/v1/games/import [post]
+func ImportGame(c *gin.Context) *errcode.Error {
+	body, cerr := uploadBody(c)
+	if cerr != nil {
+		return cerr
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, maxImportSize+1))
+	if err != nil {
+		return invalidParams("failed to read bundle: %s", err)
+	}
+	if len(data) > maxImportSize {
+		return errcode.BundleTooLarge.WithDetails(fmt.Sprintf("bundle exceeds %d bytes", maxImportSize))
+	}
+
+	bundle, err := archive.Parse(data)
+	if err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	gameID, err := service.ImportGame(c.Request.Context(), bundle)
+	if err != nil {
+		return serverError(c, errcode.ErrorImportGameFail, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"game_id": gameID,
+		"message": "Game imported successfully",
+	})
+	return nil
+}
+
+// uploadBody returns the uploaded file of a multipart request, or the
+// request body otherwise
+func uploadBody(c *gin.Context) (io.ReadCloser, *errcode.Error) {
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		return c.Request.Body, nil
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, invalidParams("missing upload: %s", err)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, invalidParams("failed to open upload: %s", err)
+	}
+	return f, nil
+}
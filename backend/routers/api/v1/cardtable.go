@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"curly-succotash/backend/internal/cardtable"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportCardsCSV exports the cards of a game as CSV.
+//
+// @Summary      Export cards as CSV
+// @Description  Exports the cards of a game as CSV with a header row. The id column is needed to import edits back.
+// @Tags         cards
+// @Produce      text/csv
+// @Param        id       path   string  true   "Game ID"
+// @Param        columns  query  string  false  "Comma separated columns (id,type,name,description,effect)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  app.ErrorResponse  "unknown column"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/export/csv [get]
+func ExportCardsCSV(c *gin.Context) *errcode.Error {
+	game, cards, cols, cerr := loadCardTable(c)
+	if cerr != nil {
+		return cerr
+	}
+	var buf bytes.Buffer
+	if err := cardtable.WriteCSV(&buf, cards, cols); err != nil {
+		return serverError(c, errcode.ErrorExportCardsFail, err)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="game_%d_cards.csv"`, game.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	return nil
+}
+
+// ExportCardsMarkdown exports a game and its cards as a Markdown document.
+//
+// @Summary      Export cards as Markdown
+// @Description  Exports the game summary and a table of its cards as Markdown for wikis.
+// @Tags         cards
+// @Produce      text/markdown
+// @Param        id       path   string  true   "Game ID"
+// @Param        columns  query  string  false  "Comma separated columns (id,type,name,description,effect)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  app.ErrorResponse  "unknown column"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/export/markdown [get]
+func ExportCardsMarkdown(c *gin.Context) *errcode.Error {
+	game, cards, cols, cerr := loadCardTable(c)
+	if cerr != nil {
+		return cerr
+	}
+	var buf bytes.Buffer
+	if err := cardtable.WriteMarkdown(&buf, game, cards, cols); err != nil {
+		return serverError(c, errcode.ErrorExportCardsFail, err)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="game_%d.md"`, game.ID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", buf.Bytes())
+	return nil
+}
+
+// ImportCardsCSV applies card edits from a CSV back to existing cards.
+//
+// @Summary      Import card edits from CSV
+// @Description  Updates existing cards of a game by ID from a CSV (raw body or multipart "file" upload). The header must include id; type, name, description and effect are optional. Nothing is written when any row is invalid; row-level errors are returned instead.
+// @Tags         cards
+// @Accept       text/csv
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id  path  string  true  "Game ID"
+// @Success      200  {object}  map[string]interface{}  "cards updated"
+// @Failure      400  {object}  app.ErrorResponse       "invalid header or row errors"
+// @Failure      403  {object}  app.ErrorResponse       "not the owner of the game"
+// @Failure      404  {object}  app.ErrorResponse       "game not found"
+// @Failure      413  {object}  app.ErrorResponse       "CSV too large"
+// @Failure      500  {object}  app.ErrorResponse       "internal server error"
+// @Router       /api/v1/games/{id}/cards/import [post]
+func ImportCardsCSV(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+
+	game, err := service.GetOwnedGame(ctx, c.Param("id"))
+	if err != nil {
+		return ownedGameError(c, errcode.ErrorImportCardsFail, err)
+	}
+
+	body, cerr := uploadBody(c)
+	if cerr != nil {
+		return cerr
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxImportSize+1))
+	if err != nil {
+		return invalidParams("failed to read CSV: %s", err)
+	}
+	if len(data) > maxImportSize {
+		return errcode.CardsCSVTooLarge.WithDetails(fmt.Sprintf("CSV exceeds %d bytes", maxImportSize))
+	}
+
+	edits, rowErrs, err := cardtable.ParseCSV(bytes.NewReader(data))
+	if err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	updated, rowErrs, err := service.ApplyCardEdits(ctx, game.ID, edits, rowErrs)
+	if err != nil {
+		return serverError(c, errcode.ErrorImportCardsFail, err)
+	}
+	if len(rowErrs) > 0 {
+		details := make([]string, 0, len(rowErrs))
+		for _, rowErr := range rowErrs {
+			details = append(details, rowErr.String())
+		}
+		return errcode.InvalidCardRows.WithDetails(details...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated":   updated,
+		"unchanged": len(edits) - updated,
+		"message":   "Cards updated successfully",
+	})
+	return nil
+}
+
+// loadCardTable fetches the game, its cards and the requested columns
+func loadCardTable(c *gin.Context) (model.Game, []model.Card, []cardtable.Column, *errcode.Error) {
+	cols, err := cardtable.ParseColumns(c.Query("columns"))
+	if err != nil {
+		return model.Game{}, nil, nil, errcode.InvalidParams.WithDetails(err.Error())
+	}
+	game, cards, err := service.GetGameWithCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return game, nil, nil, gameError(c, errcode.ErrorExportCardsFail, err)
+	}
+	return game, cards, cols, nil
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/middleware"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// invalidParams returns the response error for a malformed request
+func invalidParams(format string, args ...any) *errcode.Error {
+	return errcode.InvalidParams.WithDetails(fmt.Sprintf(format, args...))
+}
+
+// setCreatedGame records the ID of a game created by the request in its log
+// records and as the target of its audit log entry
+func setCreatedGame(c *gin.Context, id uint32) {
+	c.Set(middleware.ContextGameID, id)
+	c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "game_id", id))
+}
+
+// serverError logs err and returns fail, keeping internal details out of
+// the response
+func serverError(c *gin.Context, fail *errcode.Error, err error) *errcode.Error {
+	global.Logger.Errorf(c.Request.Context(), "%s: %s", fail.Msg(), err)
+	return fail
+}
+
+// gameError returns the response error for a failure loading a game
+func gameError(c *gin.Context, fail *errcode.Error, err error) *errcode.Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.GameNotFound
+	}
+	return serverError(c, fail, err)
+}
+
+// ownedGameError returns the response error for a failed change that
+// requires owning the game
+func ownedGameError(c *gin.Context, fail *errcode.Error, err error) *errcode.Error {
+	switch {
+	case errors.Is(err, service.ErrNotOwner):
+		return errcode.NotGameOwner
+	case errors.Is(err, service.ErrInvalidVisibility):
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+	return gameError(c, fail, err)
+}
+
+// aiError returns the response error for a failed AI-driven change
+func aiError(c *gin.Context, fail *errcode.Error, err error) *errcode.Error {
+	switch {
+	case errors.Is(err, service.ErrAIGenerate):
+		return serverError(c, errcode.ErrorAIGenerateFail, err)
+	case errors.Is(err, service.ErrAIResponse):
+		return serverError(c, errcode.ErrorAIResponseInvalid, err)
+	}
+	return gameError(c, fail, err)
+}
+
+// themeError returns the response error for a failure loading the theme
+// requested in the query string
+func themeError(c *gin.Context, fail *errcode.Error, err error) *errcode.Error {
+	if errors.Is(err, theme.ErrNotFound) {
+		return errcode.ThemeNotFound.WithDetails(fmt.Sprintf("unknown theme: %s", c.Query("theme")))
+	}
+	return serverError(c, fail, err)
+}
+
+// parseID parses a positive ID
+func parseID(name, value string) (uint32, *errcode.Error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, invalidParams("invalid %s: %q", name, value)
+	}
+	return uint32(id), nil
+}
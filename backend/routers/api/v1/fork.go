@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"net/http"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/ai"
+	"curly-succotash/backend/internal/middleware"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForkGameRequest defines the optional variations applied to a forked game
+type ForkGameRequest struct {
+	Instruction string `json:"instruction"`
+	Theme       string `json:"theme"`
+	Style       string `json:"style"`
+}
+
+// ForkGame copies a game and its cards into a new game, optionally rewritten by AI.
+//
+// @Summary      Fork a game
+// @Description  Copies a game with its cards and meta values into a new game that records the source as its parent. An optional instruction (e.g. "make it sci-fi") is applied by Gemini AI to the story and every card; theme and style override the copied values. The original game is never modified.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string           true   "Game ID"
+// @Param        body  body      ForkGameRequest  false  "Fork variations"
+// @Success      200   {object}  map[string]interface{}  "Game forked successfully"
+// @Failure      400   {object}  app.ErrorResponse       "Bad request"
+// @Failure      404   {object}  app.ErrorResponse       "game not found"
+// @Failure      429   {object}  app.ErrorResponse       "Daily generation quota exceeded"
+// @Failure      500   {object}  app.ErrorResponse       "Internal server error"
+// @Router       /api/v1/games/{id}/fork [post]
+func ForkGame(c *gin.Context) (e *errcode.Error) {
+	ctx := c.Request.Context()
+
+	var req ForkGameRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+	}
+
+	var gen service.ContentGenerator
+	if req.Instruction != "" {
+		// Only AI-driven forks count against the generation quota
+		if !middleware.ConsumeGenerationQuota(c) {
+			return nil
+		}
+		// The error response is only written once the handler returns
+		defer func() {
+			if e == nil {
+				return
+			}
+			if err := service.RefundGenerationQuota(ctx); err != nil {
+				global.Logger.Errorf(ctx, "failed to refund generation quota: %s", err)
+			}
+		}()
+		aiClient, err := ai.NewGeminiClient()
+		if err != nil {
+			metrics.Generations.WithLabelValues("fork", metrics.OutcomeError).Inc()
+			return serverError(c, errcode.ErrorAIClientFail, err)
+		}
+		defer aiClient.Close()
+		gen = aiClient
+	}
+
+	game, err := service.ForkGame(ctx, c.Param("id"), service.ForkOptions{
+		Instruction: req.Instruction,
+		Theme:       req.Theme,
+		Style:       req.Style,
+	}, gen)
+	if err != nil {
+		e := aiError(c, errcode.ErrorForkGameFail, err)
+		if gen != nil {
+			metrics.Generations.WithLabelValues("fork", metrics.StatusOutcome(e.StatusCode())).Inc()
+		}
+		return e
+	}
+	if gen != nil {
+		metrics.Generations.WithLabelValues("fork", metrics.OutcomeSuccess).Inc()
+	}
+	setCreatedGame(c, game.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"game_id":   game.ID,
+		"parent_id": game.ParentID,
+		"message":   "Game forked successfully",
+	})
+	return nil
+}
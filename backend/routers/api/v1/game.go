@@ -1,8 +1,8 @@
 package v1
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,6 +10,10 @@ import (
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/ai"
 	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -21,11 +25,15 @@ type GenerateGameRequest struct {
 	CardCount   int    `json:"cardCount" binding:"required,min=10,max=100"`
 	Style       string `json:"style" binding:"required"`
 	Description string `json:"description"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
 }
 
 // GameResponse defines the response for game queries
 type GameResponse struct {
 	ID          uint32       `json:"id"`
+	ParentID    uint32       `json:"parent_id"`
+	Owner       string       `json:"owner"`
+	Visibility  string       `json:"visibility"`
 	Theme       string       `json:"theme"`
 	CardCount   int          `json:"card_count"`
 	Style       string       `json:"style"`
@@ -48,15 +56,14 @@ type cardResponse struct {
 // @Produce      json
 // @Param        body  body      GenerateGameRequest  true  "Game generation request"
 // @Success      200   {object}  map[string]interface{}  "Game generated successfully"
-// @Failure      400   {object}  map[string]string       "Bad request"
-// @Failure      429   {object}  map[string]string       "Quota exceeded"
-// @Failure      500   {object}  map[string]string       "Internal server error"
+// @Failure      400   {object}  app.ErrorResponse       "Bad request"
+// @Failure      429   {object}  app.ErrorResponse       "Quota exceeded"
+// @Failure      500   {object}  app.ErrorResponse       "Internal server error"
 // @Router       /api/v1/game [post]
-func GenerateGame(c *gin.Context) {
+func GenerateGame(c *gin.Context) *errcode.Error {
 	var req GenerateGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return errcode.InvalidParams.WithDetails(err.Error())
 	}
 
 	ctx := c.Request.Context()
@@ -66,19 +73,17 @@ func GenerateGame(c *gin.Context) {
 	// Initialize Gemini client
 	aiClient, err := ai.NewGeminiClient()
 	if err != nil {
-		global.Logger.Errorf(ctx, "failed to initialize AI client: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to initialize AI client: %s", err)})
-		return
+		return serverError(c, errcode.ErrorAIClientFail, err)
 	}
 	defer aiClient.Close()
 
 	// Generate game description (story background)
 	prompt := fmt.Sprintf(global.StoryPromptTemplate, req.Theme)
-	storyText, err := aiClient.GenerateContent(prompt)
+	start := time.Now()
+	storyText, err := aiClient.GenerateContent(ctx, prompt)
+	metrics.ObserveStep("story", start, err)
 	if err != nil {
-		global.Logger.Errorf(ctx, "failed to generate story: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate story: %s", err)})
-		return
+		return serverError(c, errcode.ErrorAIGenerateFail, fmt.Errorf("failed to generate story: %s", err))
 	}
 	if req.Description != "" {
 		storyText = req.Description // Override with user input if provided
@@ -86,50 +91,49 @@ func GenerateGame(c *gin.Context) {
 
 	var story map[string]string
 	if err := json.Unmarshal([]byte(storyText), &story); err != nil {
-		global.Logger.Errorf(ctx, "failed to parse story JSON: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse story JSON: %s", err)})
-		return
+		return serverError(c, errcode.ErrorAIResponseInvalid, fmt.Errorf("failed to parse story JSON: %s", err))
 	}
 	if story["story_background"] == "" {
-		global.Logger.Errorf(ctx, "story background is empty")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "story background is empty"})
-		return
+		return serverError(c, errcode.ErrorAIResponseInvalid, errors.New("story background is empty"))
 	}
 	storyBackground := story["story_background"]
-	global.Logger.Infof(ctx, "Generated story: %s", storyBackground)
+	global.Logger.Infof(ctx, "Generated story of %d characters", len(storyBackground))
 
 	// Create game entry
+	if req.Visibility == "" {
+		req.Visibility = model.VisibilityPrivate
+	}
 	game := model.Game{
 		Theme:       req.Theme,
 		CardCount:   req.CardCount,
 		Style:       req.Style,
 		Description: storyBackground,
+		Visibility:  req.Visibility,
 		CreatedAt:   time.Now(),
 		Model: model.Model{
-			CreatedBy:  "system",
-			ModifiedBy: "system",
+			CreatedBy:  app.Actor(ctx),
+			ModifiedBy: app.Actor(ctx),
 			CreatedOn:  uint32(time.Now().Unix()),
 			ModifiedOn: uint32(time.Now().Unix()),
 		},
 	}
 	if err := tx.Create(&game).Error; err != nil {
-		global.Logger.Errorf(ctx, "failed to create game: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create game: %s", err)})
-		return
+		return serverError(c, errcode.ErrorCreateGameFail, err)
 	}
+	setCreatedGame(c, game.ID)
 
 	// Generate cards (roles, events, items)
 	cards, err := generateCards(c, tx, aiClient, game.ID, req.CardCount, storyBackground)
 	if err != nil {
-		global.Logger.Errorf(ctx, "failed to generate cards: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate cards: %s", err)})
-		return
+		return aiError(c, errcode.ErrorGenerateCardsFail, err)
 	}
+
+	// Save cards
+	start = time.Now()
 	for _, card := range cards {
+		card.CreatedBy, card.ModifiedBy = game.CreatedBy, game.ModifiedBy
 		if err := tx.Create(&card).Error; err != nil {
-			global.Logger.Errorf(ctx, "failed to create card: %s", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create card: %s", err)})
-			return
+			return serverError(c, errcode.ErrorCreateGameFail, fmt.Errorf("failed to create card: %s", err))
 		}
 	}
 
@@ -140,17 +144,23 @@ func GenerateGame(c *gin.Context) {
 	}
 	for _, meta := range metas {
 		if err := tx.Create(&meta).Error; err != nil {
-			global.Logger.Errorf(ctx, "failed to create meta: %s", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create meta: %s", err)})
-			return
+			return serverError(c, errcode.ErrorCreateGameFail, fmt.Errorf("failed to create meta: %s", err))
 		}
 	}
 
-	tx.Commit()
+	err = tx.Commit().Error
+	metrics.ObserveStep("save", start, err)
+	if err != nil {
+		return serverError(c, errcode.ErrorCreateGameFail, fmt.Errorf("failed to commit game: %s", err))
+	}
+	for _, card := range cards {
+		metrics.ObserveCard(card.Type)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"game_id": game.ID,
 		"message": "Game generated successfully",
 	})
+	return nil
 }
 
 // generateCards creates AI-generated role, event, and item cards
@@ -163,16 +173,18 @@ func generateCards(c *gin.Context, tx *gorm.DB, aiClient *ai.GeminiClient, gameI
 	// Role cards
 	rolePrompt := fmt.Sprintf(global.RolePrompt, 4, story)
 
-	roleText, err := aiClient.GenerateContent(rolePrompt)
+	start := time.Now()
+	roleText, err := aiClient.GenerateContent(ctx, rolePrompt)
+	metrics.ObserveStep("roles", start, err)
 	if err != nil {
 		global.Logger.Errorf(ctx, "Role generation error: %v", err)
-		return nil, fmt.Errorf("failed to generate role: %s", err)
+		return nil, fmt.Errorf("%w: failed to generate role: %s", service.ErrAIGenerate, err)
 	}
 
 	var role []cardResponse
 	if err := json.Unmarshal([]byte(roleText), &role); err != nil {
 		global.Logger.Errorf(ctx, "JSON parse error: %v", err)
-		return nil, fmt.Errorf("failed to parse role JSON: %s", err)
+		return nil, fmt.Errorf("%w: failed to parse role JSON: %s", service.ErrAIResponse, err)
 	}
 
 	for _, r := range role {
@@ -188,16 +200,18 @@ func generateCards(c *gin.Context, tx *gorm.DB, aiClient *ai.GeminiClient, gameI
 	// Event and Item cards
 	remaining := cardCount - len(cards)
 	eventPrompt := fmt.Sprintf(global.EventPrompt, remaining, story)
-	eventText, err := aiClient.GenerateContent(eventPrompt)
+	start = time.Now()
+	eventText, err := aiClient.GenerateContent(ctx, eventPrompt)
+	metrics.ObserveStep("events", start, err)
 	if err != nil {
 		global.Logger.Errorf(ctx, "Event generation error: %v", err)
-		return nil, fmt.Errorf("failed to generate event: %s", err)
+		return nil, fmt.Errorf("%w: failed to generate event: %s", service.ErrAIGenerate, err)
 	}
 
 	var event []cardResponse
 	if err := json.Unmarshal([]byte(eventText), &event); err != nil {
 		global.Logger.Errorf(ctx, "JSON parse error: %v", err)
-		return nil, fmt.Errorf("failed to parse event JSON: %s", err)
+		return nil, fmt.Errorf("%w: failed to parse event JSON: %s", service.ErrAIResponse, err)
 	}
 
 	for _, e := range event {
@@ -217,37 +231,32 @@ func generateCards(c *gin.Context, tx *gorm.DB, aiClient *ai.GeminiClient, gameI
 // GetGame handles GET requests to retrieve a game by its ID along with its associated cards.
 //
 // @Summary      Get game by ID
-// @Description  Retrieves a game and its cards by the provided game ID.
+// @Description  Retrieves a game and its cards by the provided game ID. Private games are only visible to their owner or with a share link token in the share query parameter, which replaces the JWT.
 // @Tags         game
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Game ID"
+// @Param        id     path      string  true   "Game ID"
+// @Param        share  query     string  false  "Share link token"
 // @Success      200  {object}  GameResponse
-// @Failure      404  {object}  map[string]string  "game not found"
-// @Failure      500  {object}  map[string]string  "failed to fetch cards"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "failed to fetch cards"
 // @Router       /api/v1/game/{id} [get]
-func GetGame(c *gin.Context) {
-	id := c.Param("id")
-	var game model.Game
-	ctx := context.Background()
-
-	if err := global.DBEngine.WithContext(ctx).Where("id = ? AND is_del = 0", id).First(&game).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("game not found: %s", err)})
-		return
-	}
-
-	var cards []model.Card
-	if err := global.DBEngine.WithContext(ctx).Where("game_id = ? AND is_del = 0", id).Find(&cards).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to fetch cards: %s", err)})
-		return
+func GetGame(c *gin.Context) *errcode.Error {
+	game, cards, err := service.GetGameWithCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorGetGameFail, err)
 	}
 
 	c.JSON(http.StatusOK, GameResponse{
 		ID:          game.ID,
+		ParentID:    game.ParentID,
+		Owner:       game.CreatedBy,
+		Visibility:  game.Visibility,
 		Theme:       game.Theme,
 		CardCount:   game.CardCount,
 		Style:       game.Style,
 		Description: game.Description,
 		Cards:       cards,
 	})
+	return nil
 }
@@ -1,9 +1,8 @@
 package v1
 
 import (
-	"context"
-	"curly-succotash/backend/global"
-	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,18 +11,17 @@ import (
 // ListGames handles the GET request to retrieve a list of games.
 //
 // @Summary      List games
-// @Description  Retrieves all games that are not marked as deleted.
+// @Description  Retrieves the public games and the signed-in user's own games that are not marked as deleted.
 // @Tags         games
 // @Produce      json
 // @Success      200  {array}   model.Game
-// @Failure      500  {object}  map[string]string
+// @Failure      500  {object}  app.ErrorResponse
 // @Router       /api/v1/games [get]
-func ListGames(c *gin.Context) {
-	var games []model.Game
-	ctx := context.Background()
-	if err := global.DBEngine.WithContext(ctx).Where("is_del = 0").Find(&games).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+func ListGames(c *gin.Context) *errcode.Error {
+	games, err := service.ListGames(c.Request.Context())
+	if err != nil {
+		return serverError(c, errcode.ErrorListGamesFail, err)
 	}
 	c.JSON(http.StatusOK, games)
+	return nil
 }
@@ -9,6 +9,10 @@ import (
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/model"
 	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,12 +20,23 @@ import (
 // Generator handles game generation requests
 type Generator struct{}
 
+// GeneratorRequest defines the request payload for Generator.Generate. It
+// only holds the generation inputs; IDs, ownership and deletion state are
+// set by the server.
+type GeneratorRequest struct {
+	Theme       string `json:"theme"`
+	CardCount   int    `json:"card_count"`
+	Style       string `json:"style"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
 func NewGenerator() Generator {
 	return Generator{}
 }
 
 // Generate processes a game generation request. It performs the following steps:
-// 1. Parses and validates the input JSON to create a Game object.
+// 1. Parses and validates the input JSON into a GeneratorRequest.
 // 2. Sets default values for the Game object and saves it to the database.
 // 3. Generates cards based on the game data and saves them to the database.
 // 4. Generates a PDF file containing the card details.
@@ -43,33 +58,55 @@ func NewGenerator() Generator {
 // Possible Errors:
 // - HTTP 400 Bad Request: Invalid input JSON.
 // - HTTP 500 Internal Server Error: Database save failure, card generation failure, or PDF generation failure.
-func (g *Generator) Generate(c *gin.Context) {
-	var input model.Game
-	if err := c.ShouldBindJSON(&input); err != nil {
-		global.Logger.Errorf(c, "Invalid input: %s", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
-		return
+func (g *Generator) Generate(c *gin.Context) *errcode.Error {
+	var req GeneratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return invalidParams("Invalid input: %s", err)
+	}
+
+	// Load the card theme used for the PDF
+	t, err := theme.Load(c.Query("theme"))
+	if err != nil {
+		return themeError(c, errcode.ErrorCreateGameFail, err)
+	}
+
+	// New games are private unless requested otherwise
+	if req.Visibility == "" {
+		req.Visibility = model.VisibilityPrivate
+	}
+	if !service.ValidVisibility(req.Visibility) {
+		return invalidParams("Invalid input: unknown visibility %s", req.Visibility)
 	}
 
-	// Set default values for Model fields
-	input.CreatedBy = "system"
-	input.ModifiedBy = "system"
-	input.CreatedAt = time.Now()
+	actor := app.Actor(c.Request.Context())
+	input := model.Game{
+		Theme:       req.Theme,
+		CardCount:   req.CardCount,
+		Style:       req.Style,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		CreatedAt:   time.Now(),
+		Model: model.Model{
+			CreatedBy:  actor,
+			ModifiedBy: actor,
+			CreatedOn:  uint32(time.Now().Unix()),
+			ModifiedOn: uint32(time.Now().Unix()),
+		},
+	}
 
 	// Save game data using GORM
-	if err := global.DBEngine.Create(&input).Error; err != nil {
-		global.Logger.Errorf(c, "Failed to save game: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save game: " + err.Error()})
-		return
+	if err := global.DBEngine.WithContext(c.Request.Context()).Create(&input).Error; err != nil {
+		return serverError(c, errcode.ErrorCreateGameFail, err)
 	}
 	gameID := input.ID
+	setCreatedGame(c, gameID)
 
 	// Generate cards
+	start := time.Now()
 	cards, err := service.GenerateCards(c, input)
+	metrics.ObserveStep("cards", start, err)
 	if err != nil {
-		global.Logger.Errorf(c, "Failed to generate cards: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate cards: " + err.Error()})
-		return
+		return serverError(c, errcode.ErrorGenerateCardsFail, err)
 	}
 
 	// Save cards using GORM
@@ -80,31 +117,34 @@ func (g *Generator) Generate(c *gin.Context) {
 			Description: card.Description,
 			Effect:      card.Effect,
 			Model: model.Model{
-				CreatedBy:  "system",
-				ModifiedBy: "system",
+				CreatedBy:  actor,
+				ModifiedBy: actor,
 				CreatedOn:  uint32(time.Now().Unix()),
 				ModifiedOn: uint32(time.Now().Unix()),
 				DeletedOn:  0,
 				IsDel:      0},
 		}
-		if err := global.DBEngine.Create(&dbCard).Error; err != nil {
-			global.Logger.Errorf(c, "Failed to save card: %s", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save card: " + err.Error()})
-			return
+		if err := global.DBEngine.WithContext(c.Request.Context()).Create(&dbCard).Error; err != nil {
+			return serverError(c, errcode.ErrorCreateGameFail, fmt.Errorf("failed to save card: %s", err))
 		}
 	}
 
+	for _, card := range cards {
+		metrics.ObserveCard(card.Type)
+	}
+
 	// Generate PDF
-	pdfPath, err := service.GeneratePDF(c, cards)
+	start = time.Now()
+	pdfPath, err := service.GeneratePDF(c, cards, t)
+	metrics.ObserveStep("pdf", start, err)
 	if err != nil {
-		global.Logger.Errorf(c, "Failed to generate PDF: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF: " + err.Error()})
-		return
+		return serverError(c, errcode.ErrorRenderPDFFail, err)
 	}
 
 	// Return result
 	c.JSON(http.StatusOK, gin.H{
 		"cards":  cards,
-		"pdfUrl": fmt.Sprintf("http://localhost:%s/%s/%s", global.ServerSetting.HttpPort, global.StoragePathSetting.PDFFoldar, filepath.Base(pdfPath)),
+		"pdfUrl": fmt.Sprintf("http://localhost:%s/%s/%s", global.ServerSetting.Load().HttpPort, global.StoragePathSetting.Load().PDFFoldar, filepath.Base(pdfPath)),
 	})
+	return nil
 }
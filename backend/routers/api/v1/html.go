@@ -2,17 +2,26 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/pdfcache"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/errcode"
+	"curly-succotash/backend/pkg/metrics"
+	"curly-succotash/backend/pkg/tracer"
 
 	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GenerateHTMLPDF generates a PDF using HTML and wkhtmltopdf
@@ -21,151 +30,158 @@ import (
 // renders an HTML template using the game and card data, converts the HTML to a PDF using wkhtmltopdf,
 // saves the PDF to the server, and serves the generated PDF file as a response.
 //
+// Rendered PDFs are cached under a hash of the game content, theme and layout options.
+// The hash doubles as the ETag so clients can revalidate with If-None-Match.
+//
 // API
 // @Summary      Generate PDF for a board game
 // @Description  Generates a PDF file containing the board game's details and its cards, and returns the PDF file.
 // @Tags         games
 // @Produce      application/pdf
-// @Param        id   path      string  true  "Game ID"
+// @Param        id     path      string  true   "Game ID"
+// @Param        theme  query     string  false  "Card theme name, see /api/v1/themes"
+// @Param        page_size  query  string  false  "Page size: A4 (default), A5, Letter or Legal"
+// @Param        share  query     string  false  "Share link token, replaces the JWT"
+// @Param        If-None-Match  header  string  false  "ETag of a previously downloaded PDF"
 // @Success      200  {file}    file    "PDF file"
-// @Failure      404  {object}  map[string]string  "game not found"
-// @Failure      500  {object}  map[string]string  "internal server error"
-// @Router       /api/v1/games/{id}/pdf [get]
-func GenerateHTMLPDF(c *gin.Context) {
-	id := c.Param("id")
+// @Success      304  "PDF not modified"
+// @Failure      400  {object}  app.ErrorResponse  "unknown theme"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/generate-pdf/{id} [get]
+func GenerateHTMLPDF(c *gin.Context) *errcode.Error {
 	ctx := c.Request.Context()
 
-	// Fetch game
-	var game model.Game
-	if err := global.DBEngine.WithContext(ctx).Where("id = ? AND is_del = 0", id).First(&game).Error; err != nil {
-		global.Logger.Errorf(c, "game not found: %s", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("game not found: %s", err)})
-		return
+	// Fetch game and cards visible to the request or share link
+	game, cards, err := service.GetGameWithCards(ctx, c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorRenderPDFFail, err)
+	}
+
+	// Load card theme
+	t, err := theme.Load(c.Query("theme"))
+	if err != nil {
+		return themeError(c, errcode.ErrorRenderPDFFail, err)
 	}
 
-	// Fetch cards
-	var cards []model.Card
-	if err := global.DBEngine.WithContext(ctx).Where("game_id = ? AND is_del = 0", id).Find(&cards).Error; err != nil {
-		global.Logger.Errorf(c, "failed to fetch cards: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to fetch cards: %s", err)})
-		return
+	// Resolve layout options
+	layout := pdfLayout{PageSize: c.DefaultQuery("page_size", wkhtmltopdf.PageSizeA4), Margin: 10}
+	if !validPageSizes[layout.PageSize] {
+		return invalidParams("unsupported page size: %s", layout.PageSize)
 	}
 
-	// HTML template
-	tmpl := `
-	<!DOCTYPE html>
-	<html>
-	<head>
-		<meta charset="UTF-8">
-		<link href="https://cdn.jsdelivr.net/npm/tailwindcss@2.2.19/dist/tailwind.min.css" rel="stylesheet">
-		<style>
-			@page { margin: 10mm; }
-			body { font-family: Arial, sans-serif; }
-			.card { 
-				width: 250px; 
-				height: 350px; 
-				border: 2px solid black; 
-				border-radius: 8px; 
-				padding: 10px; 
-				margin: 10px; 
-				float: left; 
-				background-color: #f9fafb;
-				box-shadow: 2px 2px 5px rgba(0,0,0,0.2);
-			}
-			.card-title { font-size: 18px; font-weight: bold; color: #1f2937; }
-			.card-type { font-size: 14px; color: #6b7280; }
-			.card-desc, .card-effect { 
-				font-size: 12px; 
-				margin-top: 8px; 
-				color: #374151; 
-				line-height: 1.4; 
-				max-height: 120px; 
-				overflow: hidden; 
-			}
-			.page-break { clear: both; page-break-after: always; }
-			.header { margin-bottom: 20px; }
-		</style>
-	</head>
-	<body>
-		<div class="header">
-			<h1 class="text-2xl font-bold mb-4">Board Game: {{.Game.Theme}}</h1>
-			<p class="text-base mb-4">Story: {{.Game.Description}}</p>
-			<h2 class="text-xl font-bold mb-4">Cards (Game ID: {{.Game.ID}})</h2>
-		</div>
-		{{range $i, $card := .Cards}}
-			<div class="card">
-				<div class="card-title">{{.Name}}</div>
-				<div class="card-type">({{.Type | title}})</div>
-				<div class="card-desc">Description: {{.Description}}</div>
-				<div class="card-effect">Effect: {{.Effect}}</div>
-			</div>
-			{{if eq (mod $i 4) 3}}<div class="page-break"></div>{{end}}
-		{{end}}
-	</body>
-	</html>`
-
-	// Parse template
-	t, err := template.New("pdf").Funcs(template.FuncMap{
-		"mod": func(i, n int) int { return i % n },
-		"title": func(s string) string {
-			if len(s) == 0 {
-				return s
-			}
-			return string(s[0]-32) + s[1:]
-		},
-	}).Parse(tmpl)
+	// Serve from cache when the content has not changed
+	key, err := pdfcache.Key(game, cards, t.Name, t.Fingerprint(), layout)
 	if err != nil {
-		global.Logger.Errorf(c, "failed to parse template: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse template: %s", err)})
-		return
+		return serverError(c, errcode.ErrorRenderPDFFail, fmt.Errorf("failed to compute cache key: %s", err))
+	}
+	etag := fmt.Sprintf("%q", key)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return nil
+	}
+	pdfPath, ok := pdfcache.Lookup(game.ID, key, ".pdf")
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("pdf.cache_hit", ok))
+	if ok {
+		c.File(pdfPath)
+		return nil
 	}
 
 	// Render HTML
 	data := struct {
-		Game  model.Game
-		Cards []model.Card
-	}{game, cards}
+		Game       model.Game
+		Cards      []model.Card
+		Theme      *theme.Theme
+		FontCSS    template.CSS
+		FontFamily template.CSS
+	}{game, cards, t, template.CSS(global.Fonts.CSS()), template.CSS(global.Fonts.FamilyStack())}
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
-		global.Logger.Errorf(c, "failed to render template: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to render template: %s", err)})
-		return
-	}
-
-	// Create PDF generator
-	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	_, span := tracer.Start(ctx, "pdf.render_html")
+	err = t.HTML().Execute(&buf, data)
+	span.SetAttributes(attribute.Int("pdf.html_size", buf.Len()))
+	tracer.End(span, err)
 	if err != nil {
-		global.Logger.Errorf(c, "failed to create PDF generator: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create PDF generator: %s", err)})
-		return
+		return serverError(c, errcode.ErrorRenderPDFFail, fmt.Errorf("failed to render template: %s", err))
 	}
-	pdfg.AddPage(wkhtmltopdf.NewPageReader(bytes.NewReader(buf.Bytes())))
-	pdfg.MarginTop.Set(10)
-	pdfg.MarginBottom.Set(10)
-	pdfg.MarginLeft.Set(10)
-	pdfg.MarginRight.Set(10)
 
 	// Generate PDF
-	if err := pdfg.Create(); err != nil {
-		global.Logger.Errorf(c, "failed to generate PDF: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate PDF: %s", err)})
-		return
+	pdf, err := renderHTMLPDF(ctx, buf.Bytes(), layout, len(cards))
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderPDFFail, err)
 	}
 
 	// Save PDF
-	outputDir := "./files"
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		global.Logger.Errorf(c, "failed to create directory: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create directory: %s", err)})
-		return
-	}
-	pdfPath := filepath.Join(outputDir, fmt.Sprintf("game_%s.pdf", id))
-	if err := pdfg.WriteFile(pdfPath); err != nil {
-		global.Logger.Errorf(c, "failed to save PDF: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save PDF: %s", err)})
-		return
+	pdfPath, err = pdfcache.Store(game.ID, key, ".pdf", pdf)
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderPDFFail, fmt.Errorf("failed to save PDF: %s", err))
 	}
 
 	// Serve PDF
 	c.File(pdfPath)
+	return nil
+}
+
+// renderHTMLPDF converts rendered HTML to a PDF with wkhtmltopdf
+func renderHTMLPDF(ctx context.Context, html []byte, layout pdfLayout, cardCount int) (pdf []byte, err error) {
+	_, span := tracer.Start(ctx, "pdf.wkhtmltopdf")
+	span.SetAttributes(
+		attribute.String("pdf.page_size", layout.PageSize),
+		attribute.Int("pdf.card_count", cardCount),
+	)
+	start := time.Now()
+	defer func() {
+		metrics.PDFRenderDuration.WithLabelValues("wkhtmltopdf", metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+		tracer.End(span, err)
+	}()
+
+	// Create PDF generator
+	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF generator: %s", err)
+	}
+	page := wkhtmltopdf.NewPageReader(bytes.NewReader(html))
+	// Embedded fonts are referenced with file:// URLs
+	page.EnableLocalFileAccess.Set(true)
+	pdfg.AddPage(page)
+	pdfg.PageSize.Set(layout.PageSize)
+	pdfg.MarginTop.Set(layout.Margin)
+	pdfg.MarginBottom.Set(layout.Margin)
+	pdfg.MarginLeft.Set(layout.Margin)
+	pdfg.MarginRight.Set(layout.Margin)
+
+	if err := pdfg.Create(); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %s", err)
+	}
+	pdf = pdfg.Bytes()
+	span.SetAttributes(attribute.Int("pdf.size", len(pdf)))
+	metrics.PDFSize.WithLabelValues("wkhtmltopdf").Observe(float64(len(pdf)))
+	return pdf, nil
+}
+
+// pdfLayout holds the page options that change a rendered PDF
+type pdfLayout struct {
+	PageSize string `json:"page_size"`
+	Margin   uint   `json:"margin"`
+}
+
+var validPageSizes = map[string]bool{
+	wkhtmltopdf.PageSizeA4:     true,
+	wkhtmltopdf.PageSizeA5:     true,
+	wkhtmltopdf.PageSizeLetter: true,
+	wkhtmltopdf.PageSizeLegal:  true,
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*".
+// The header may list several ETags separated by commas, and ETags compare
+// weakly, ignoring a W/ prefix.
+func etagMatches(header, etag string) bool {
+	for _, match := range strings.Split(header, ",") {
+		match = strings.TrimSpace(match)
+		if match == "*" || strings.TrimPrefix(match, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,30 @@
+package v1
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"a374503442dde64d"`
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"empty", "", false},
+		{"exact", `"a374503442dde64d"`, true},
+		{"weak", `W/"a374503442dde64d"`, true},
+		{"other", `"0000000000000000"`, false},
+		{"unquoted", `a374503442dde64d`, false},
+		{"list", `"0000000000000000", "a374503442dde64d"`, true},
+		{"list without spaces", `"0000000000000000",W/"a374503442dde64d"`, true},
+		{"list without match", `"0000000000000000", W/"1111111111111111"`, false},
+		{"any", `*`, true},
+		{"lowercase weak prefix", `w/"a374503442dde64d"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
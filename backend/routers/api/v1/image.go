@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/cardimage"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// imageOptions holds the query parameters shared by the card image endpoints
+type imageOptions struct {
+	format cardimage.Format
+	width  int
+	theme  *theme.Theme
+}
+
+// parseImageOptions reads format, size and theme from the query string
+func parseImageOptions(c *gin.Context) (imageOptions, *errcode.Error) {
+	var opts imageOptions
+	var err error
+
+	opts.format, err = cardimage.ParseFormat(c.Query("format"))
+	if err != nil {
+		return opts, invalidParams("unsupported format: %s", c.Query("format"))
+	}
+
+	opts.width = cardimage.DefaultWidth
+	if size := c.Query("size"); size != "" {
+		opts.width, err = strconv.Atoi(size)
+		if err != nil || opts.width < cardimage.MinWidth || opts.width > cardimage.MaxWidth {
+			return opts, invalidParams("size must be a width in pixels between %d and %d", cardimage.MinWidth, cardimage.MaxWidth)
+		}
+	}
+
+	opts.theme, err = theme.Load(c.Query("theme"))
+	if err != nil {
+		return opts, themeError(c, errcode.ErrorRenderImageFail, err)
+	}
+	return opts, nil
+}
+
+// GetCardImage renders a single card as an image.
+//
+// @Summary      Render a card image
+// @Description  Renders one card of a game as a PNG or SVG image using the selected theme.
+// @Tags         cards
+// @Produce      image/png
+// @Produce      image/svg+xml
+// @Param        id      path   string  true   "Game ID"
+// @Param        cardId  path   string  true   "Card ID"
+// @Param        format  query  string  false  "png (default) or svg"
+// @Param        size    query  int     false  "Image width in pixels (150-2000, default 750)"
+// @Param        theme   query  string  false  "Card theme name"
+// @Success      200  {file}    file
+// @Failure      400  {object}  app.ErrorResponse  "invalid parameters"
+// @Failure      404  {object}  app.ErrorResponse  "card not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/cards/{cardId}/image [get]
+func GetCardImage(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	opts, cerr := parseImageOptions(c)
+	if cerr != nil {
+		return cerr
+	}
+
+	game, err := service.GetGame(ctx, c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorRenderImageFail, err)
+	}
+	var card model.Card
+	if err := global.DBEngine.WithContext(ctx).Where("id = ? AND game_id = ? AND is_del = 0", c.Param("cardId"), game.ID).First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.CardNotFound
+		}
+		return serverError(c, errcode.ErrorRenderImageFail, err)
+	}
+
+	var buf bytes.Buffer
+	if err := cardimage.Render(&buf, card, opts.theme, opts.format, opts.width); err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, err)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="card_%d.%s"`, card.ID, opts.format))
+	c.Data(http.StatusOK, opts.format.ContentType(), buf.Bytes())
+	return nil
+}
+
+// GetCardImages renders every card of a game and returns them as a ZIP archive.
+//
+// @Summary      Download all card images
+// @Description  Renders all cards of a game as PNG or SVG images using the selected theme and bundles them into a ZIP archive.
+// @Tags         cards
+// @Produce      application/zip
+// @Param        id      path   string  true   "Game ID"
+// @Param        format  query  string  false  "png (default) or svg"
+// @Param        size    query  int     false  "Image width in pixels (150-2000, default 750)"
+// @Param        theme   query  string  false  "Card theme name"
+// @Success      200  {file}    file
+// @Failure      400  {object}  app.ErrorResponse  "invalid parameters"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/cards/images [get]
+func GetCardImages(c *gin.Context) *errcode.Error {
+	opts, cerr := parseImageOptions(c)
+	if cerr != nil {
+		return cerr
+	}
+
+	game, cards, err := service.GetGameWithCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorRenderImageFail, err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, card := range cards {
+		f, err := zw.Create(fmt.Sprintf("%03d_%s_%d.%s", i+1, card.Type, card.ID, opts.format))
+		if err != nil {
+			return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to create archive entry: %s", err))
+		}
+		if err := cardimage.Render(f, card, opts.theme, opts.format, opts.width); err != nil {
+			return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to render card %d: %s", card.ID, err))
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to finish archive: %s", err))
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="game_%d_cards.zip"`, game.ID))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	return nil
+}
@@ -2,9 +2,9 @@ package v1
 
 import (
 	"curly-succotash/backend/global"
-	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 
@@ -12,38 +12,31 @@ import (
 	"github.com/jung-kurt/gofpdf"
 )
 
-func GeneratePDF(c *gin.Context) {
-	id := c.Param("id")
-	ctx := c.Request.Context()
-	var game model.Game
-	if err := global.DBEngine.WithContext(ctx).Where("id = ? AND is_del = 0", id).First(&game).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("game not found: %s", err)})
-		return
-	}
-	var cards []model.Card
-	if err := global.DBEngine.WithContext(ctx).Where("game_id = ? AND is_del = 0", id).Find(&cards).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to fetch cards: %s", err)})
-		return
+func GeneratePDF(c *gin.Context) *errcode.Error {
+	game, cards, err := service.GetGameWithCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorRenderPDFFail, err)
 	}
 	pdf := gofpdf.New("P", "mm", "A4", "")
+	global.Fonts.RegisterPDF(pdf)
 	pdf.AddPage()
 
 	for _, card := range cards {
-		pdf.Cell(0, 10, fmt.Sprintf("%s (%s)", card.Name, card.Type))
-		pdf.Ln(8)
-		pdf.MultiCell(0, 8, "Description: "+card.Description, "", "", false)
-		pdf.MultiCell(0, 8, "Effect: "+card.Effect, "", "", false)
+		global.Fonts.WritePDF(pdf, "", 12, 10, fmt.Sprintf("%s (%s)", card.Name, card.Type))
+		pdf.Ln(10)
+		global.Fonts.WritePDF(pdf, "", 12, 8, "Description: "+card.Description)
 		pdf.Ln(8)
+		global.Fonts.WritePDF(pdf, "", 12, 8, "Effect: "+card.Effect)
+		pdf.Ln(16)
 	}
 	outputDir := "./files"
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create directory: %s", err)})
-		return
+		return serverError(c, errcode.ErrorRenderPDFFail, fmt.Errorf("failed to create directory: %s", err))
 	}
-	pdfPath := filepath.Join(outputDir, fmt.Sprintf("game_%s.pdf", id))
+	pdfPath := filepath.Join(outputDir, fmt.Sprintf("game_%d.pdf", game.ID))
 	if err := pdf.OutputFileAndClose(pdfPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to generate PDF: %s", err)})
-		return
+		return serverError(c, errcode.ErrorRenderPDFFail, err)
 	}
 	c.File(pdfPath)
+	return nil
 }
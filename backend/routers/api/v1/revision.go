@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRevisions lists the change history of a game.
+//
+// @Summary      List game revisions
+// @Description  Lists the revisions recorded for a game and its cards, oldest first. Each revision holds a snapshot of the changed game or card, the action and who made it.
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Success      200  {array}   model.Revision
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/revisions [get]
+func ListRevisions(c *gin.Context) *errcode.Error {
+	revisions, err := service.ListRevisions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorListRevisionsFail, err)
+	}
+	c.JSON(http.StatusOK, revisions)
+	return nil
+}
+
+// DiffRevisions compares a game between two revisions.
+//
+// @Summary      Diff game revisions
+// @Description  Compares the game and its cards as of two revisions, listing changed game fields and added, removed or modified cards.
+// @Tags         games
+// @Produce      json
+// @Param        id    path      string  true  "Game ID"
+// @Param        from  query     int     true  "Older revision ID"
+// @Param        to    query     int     true  "Newer revision ID"
+// @Success      200   {object}  service.RevisionDiff
+// @Failure      400   {object}  app.ErrorResponse  "invalid revision ID"
+// @Failure      404   {object}  app.ErrorResponse  "game or revision not found"
+// @Failure      500   {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/revisions/diff [get]
+func DiffRevisions(c *gin.Context) *errcode.Error {
+	gameID, cerr := parseID("game ID", c.Param("id"))
+	if cerr != nil {
+		return cerr
+	}
+	from, cerr := parseID("from", c.Query("from"))
+	if cerr != nil {
+		return cerr
+	}
+	to, cerr := parseID("to", c.Query("to"))
+	if cerr != nil {
+		return cerr
+	}
+
+	diff, err := service.DiffRevisions(c.Request.Context(), gameID, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			return errcode.RevisionNotFound.WithDetails(err.Error())
+		}
+		return gameError(c, errcode.ErrorDiffRevisionsFail, err)
+	}
+	c.JSON(http.StatusOK, diff)
+	return nil
+}
+
+// RollbackGame restores a game to a revision.
+//
+// @Summary      Roll back a game
+// @Description  Restores the game and its cards to their state as of a revision. Cards added since are removed and removed cards are restored; the rollback is recorded as new revisions.
+// @Tags         games
+// @Produce      json
+// @Param        id          path      string  true  "Game ID"
+// @Param        revisionId  path      int     true  "Revision ID"
+// @Success      200         {object}  service.RollbackResult
+// @Failure      400         {object}  app.ErrorResponse  "invalid revision ID"
+// @Failure      403         {object}  app.ErrorResponse  "not the owner of the game"
+// @Failure      404         {object}  app.ErrorResponse  "game or revision not found"
+// @Failure      500         {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/revisions/{revisionId}/rollback [post]
+func RollbackGame(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	gameID, cerr := parseID("game ID", c.Param("id"))
+	if cerr != nil {
+		return cerr
+	}
+	revisionID, cerr := parseID("revision ID", c.Param("revisionId"))
+	if cerr != nil {
+		return cerr
+	}
+
+	result, err := service.RollbackGame(ctx, gameID, revisionID)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			return errcode.RevisionNotFound.WithDetails(err.Error())
+		}
+		return ownedGameError(c, errcode.ErrorRollbackGameFail, err)
+	}
+	c.JSON(http.StatusOK, result)
+	return nil
+}
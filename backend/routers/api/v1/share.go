@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VisibilityRequest defines the request payload for changing a game's visibility
+type VisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required,oneof=private unlisted public"`
+}
+
+// ShareRequest defines the request payload for creating a share link
+type ShareRequest struct {
+	ExpiresIn int64 `json:"expires_in" binding:"omitempty,min=60"` // seconds
+}
+
+// ShareResponse holds a share link token and the URLs it unlocks
+type ShareResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+	GameURL   string `json:"game_url"`
+	PDFURL    string `json:"pdf_url"`
+}
+
+// SetVisibility changes who can see a game.
+//
+// @Summary      Set game visibility
+// @Description  Sets a game owned by the signed-in user to private (owner and share links only), unlisted (anyone with the ID, not listed) or public (listed to everyone).
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string             true  "Game ID"
+// @Param        body  body      VisibilityRequest  true  "Visibility"
+// @Success      200   {object}  map[string]interface{}  "Visibility updated successfully"
+// @Failure      400   {object}  app.ErrorResponse       "Bad request"
+// @Failure      403   {object}  app.ErrorResponse       "not the owner of the game"
+// @Failure      404   {object}  app.ErrorResponse       "game not found"
+// @Failure      500   {object}  app.ErrorResponse       "Internal server error"
+// @Router       /api/v1/games/{id}/visibility [put]
+func SetVisibility(c *gin.Context) *errcode.Error {
+	var req VisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+
+	game, err := service.SetVisibility(c.Request.Context(), c.Param("id"), req.Visibility)
+	if err != nil {
+		return ownedGameError(c, errcode.ErrorUpdateGameFail, err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"game_id":    game.ID,
+		"visibility": game.Visibility,
+		"message":    "Visibility updated successfully",
+	})
+	return nil
+}
+
+// CreateShareLink creates a signed, expiring read-only link to a game.
+//
+// @Summary      Create a share link
+// @Description  Signs a token granting read-only access to a game owned by the signed-in user and its PDF, whatever its visibility. Pass it as the share query parameter instead of a JWT. Links expire after expires_in seconds (default 7 days, at most 30 days).
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string        true   "Game ID"
+// @Param        body  body      ShareRequest  false  "Expiry"
+// @Success      200   {object}  ShareResponse
+// @Failure      400   {object}  app.ErrorResponse  "Bad request"
+// @Failure      403   {object}  app.ErrorResponse  "not the owner of the game"
+// @Failure      404   {object}  app.ErrorResponse  "game not found"
+// @Failure      500   {object}  app.ErrorResponse  "Internal server error"
+// @Router       /api/v1/games/{id}/share [post]
+func CreateShareLink(c *gin.Context) *errcode.Error {
+	var req ShareRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return errcode.InvalidParams.WithDetails(err.Error())
+		}
+	}
+	expire := service.DefaultShareExpire
+	if req.ExpiresIn > 0 {
+		expire = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if expire > service.MaxShareExpire {
+		return invalidParams("expires_in must not exceed %d seconds", int64(service.MaxShareExpire/time.Second))
+	}
+
+	token, expiresAt, err := service.CreateShareLink(c.Request.Context(), c.Param("id"), expire)
+	if err != nil {
+		return ownedGameError(c, errcode.ErrorShareGameFail, err)
+	}
+	query := "?share=" + url.QueryEscape(token)
+	c.JSON(http.StatusOK, ShareResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Unix(),
+		GameURL:   publicURL(c, fmt.Sprintf("/api/v1/games/%s%s", c.Param("id"), query)),
+		PDFURL:    publicURL(c, fmt.Sprintf("/api/v1/generate-pdf/%s%s", c.Param("id"), query)),
+	})
+	return nil
+}
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"net/http"
+
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListThemes handles the GET request to retrieve the available card themes.
+//
+// @Summary      List card themes
+// @Description  Retrieves the card themes that can be selected when exporting a game.
+// @Tags         themes
+// @Produce      json
+// @Success      200  {array}   theme.Theme
+// @Failure      500  {object}  app.ErrorResponse
+// @Router       /api/v1/themes [get]
+func ListThemes(c *gin.Context) *errcode.Error {
+	themes, err := theme.List()
+	if err != nil {
+		return serverError(c, errcode.ErrorListThemesFail, err)
+	}
+	c.JSON(http.StatusOK, themes)
+	return nil
+}
@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"net/http"
+
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageQuery selects the days and grouping of token usage totals
+type UsageQuery struct {
+	Group string `form:"group" binding:"omitempty,oneof=game user day model"`
+	From  string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To    string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// GetGameUsage returns the AI token usage and cost of a game.
+//
+// @Summary      Game token usage
+// @Description  Returns the AI tokens and cost in USD spent generating, forking and regenerating a game, per model. Only the owner can see them.
+// @Tags         usage
+// @Produce      json
+// @Param        id    path      string  true  "Game ID"
+// @Success      200   {object}  service.UsageReport
+// @Failure      403   {object}  app.ErrorResponse  "not the game's owner"
+// @Failure      404   {object}  app.ErrorResponse  "game not found"
+// @Failure      500   {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/usage [get]
+func GetGameUsage(c *gin.Context) *errcode.Error {
+	ctx := c.Request.Context()
+	game, err := service.GetOwnedGame(ctx, c.Param("id"))
+	if err != nil {
+		return ownedGameError(c, errcode.ErrorGetUsageFail, err)
+	}
+	report, err := service.UsageTotals(ctx, service.UsageFilter{GameID: game.ID}, service.UsageByModel)
+	if err != nil {
+		return serverError(c, errcode.ErrorGetUsageFail, err)
+	}
+	c.JSON(http.StatusOK, report)
+	return nil
+}
+
+// GetUsage returns the signed-in user's AI token usage and cost.
+//
+// @Summary      Token usage
+// @Description  Returns the AI tokens and cost in USD of the signed-in user's requests, grouped by day (default), game or model.
+// @Tags         usage
+// @Produce      json
+// @Param        group  query     string  false  "Grouping: day, game or model"
+// @Param        from   query     string  false  "First UTC day, YYYY-MM-DD"
+// @Param        to     query     string  false  "Last UTC day, YYYY-MM-DD"
+// @Success      200    {object}  service.UsageReport
+// @Failure      400    {object}  app.ErrorResponse  "invalid grouping or day"
+// @Failure      500    {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/usage [get]
+func GetUsage(c *gin.Context) *errcode.Error {
+	var q UsageQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+	if q.Group == "" {
+		q.Group = service.UsageByDay
+	}
+	ctx := c.Request.Context()
+	report, err := service.UsageTotals(ctx, service.UsageFilter{Actor: app.Actor(ctx), From: q.From, To: q.To}, q.Group)
+	if err != nil {
+		return serverError(c, errcode.ErrorGetUsageFail, err)
+	}
+	c.JSON(http.StatusOK, report)
+	return nil
+}
@@ -0,0 +1,255 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"curly-succotash/backend/internal/cardimage"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/pdfcache"
+	"curly-succotash/backend/internal/service"
+	"curly-succotash/backend/internal/theme"
+	"curly-succotash/backend/internal/vtt"
+	"curly-succotash/backend/pkg/app"
+	"curly-succotash/backend/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sheetCardWidth keeps a 10 x 7 sheet at 4000px wide, within the size
+// Tabletop Simulator loads reliably
+const sheetCardWidth = 400
+
+// ExportTTS exports a game as a Tabletop Simulator saved object.
+//
+// @Summary      Export a Tabletop Simulator deck
+// @Description  Renders the cards onto 10x7 card sheet images and returns a Tabletop Simulator saved-object JSON referencing them. The image URLs carry a share token valid for 7 days. Save it under "Saved Objects" to load the deck.
+// @Tags         export
+// @Produce      json
+// @Param        id     path   string  true   "Game ID"
+// @Param        theme  query  string  false  "Card theme name"
+// @Success      200  {object}  vtt.TTSSave
+// @Failure      400  {object}  app.ErrorResponse  "unknown theme"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/export/tts [get]
+func ExportTTS(c *gin.Context) *errcode.Error {
+	game, cards, t, cerr := loadVTTGame(c)
+	if cerr != nil {
+		return cerr
+	}
+
+	fileURL, err := exportFileURL(c, game.ID)
+	if err != nil {
+		return serverError(c, errcode.ErrorExportGameFail, err)
+	}
+	backURL, err := storeCardBack(fileURL, game, cards, t)
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to render card back: %s", err))
+	}
+	sheetURLs, err := storeCardSheets(fileURL, game, cards, t)
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to render card sheets: %s", err))
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="game_%d_tts.json"`, game.ID))
+	c.JSON(http.StatusOK, vtt.BuildTTS(game, cards, sheetURLs, backURL))
+	return nil
+}
+
+// ExportVTT exports a game in the generic virtual tabletop JSON format.
+//
+// @Summary      Export a generic virtual tabletop deck
+// @Description  Returns the game's cards with per-card image URLs and a card back image for import into virtual tabletops without a dedicated exporter. The image URLs carry a share token valid for 7 days.
+// @Tags         export
+// @Produce      json
+// @Param        id     path   string  true   "Game ID"
+// @Param        theme  query  string  false  "Card theme name"
+// @Success      200  {object}  vtt.Deck
+// @Failure      400  {object}  app.ErrorResponse  "unknown theme"
+// @Failure      404  {object}  app.ErrorResponse  "game not found"
+// @Failure      500  {object}  app.ErrorResponse  "internal server error"
+// @Router       /api/v1/games/{id}/export/vtt [get]
+func ExportVTT(c *gin.Context) *errcode.Error {
+	game, cards, t, cerr := loadVTTGame(c)
+	if cerr != nil {
+		return cerr
+	}
+
+	fileURL, err := exportFileURL(c, game.ID)
+	if err != nil {
+		return serverError(c, errcode.ErrorExportGameFail, err)
+	}
+	backURL, err := storeCardBack(fileURL, game, cards, t)
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to render card back: %s", err))
+	}
+
+	imageURLs, err := storeCardImages(fileURL, game, cards, t)
+	if err != nil {
+		return serverError(c, errcode.ErrorRenderImageFail, fmt.Errorf("failed to render card images: %s", err))
+	}
+
+	size := vtt.CardSize{WidthMM: t.Layout.CardWidth, HeightMM: t.Layout.CardHeight}
+	deck := vtt.BuildDeck(game, cards, size, backURL, func(card model.Card) string {
+		return imageURLs[card.ID]
+	})
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="game_%d_vtt.json"`, game.ID))
+	c.JSON(http.StatusOK, deck)
+	return nil
+}
+
+// GetExportFile serves an image rendered by ExportTTS or ExportVTT.
+//
+// @Summary      Download an exported image
+// @Description  Serves a card, card sheet or card back image referenced by a Tabletop Simulator or virtual tabletop export. The export URLs carry a share token valid for 7 days.
+// @Tags         export
+// @Produce      png
+// @Param        id     path   string  true   "Game ID"
+// @Param        name   path   string  true   "File name from the export"
+// @Param        share  query  string  false  "Share link token, instead of a JWT"
+// @Success      200  {file}    binary
+// @Failure      401  {object}  app.ErrorResponse  "unauthorized"
+// @Failure      404  {object}  app.ErrorResponse  "game or file not found"
+// @Router       /api/v1/games/{id}/export/files/{name} [get]
+func GetExportFile(c *gin.Context) *errcode.Error {
+	game, err := service.GetGame(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return gameError(c, errcode.ErrorExportGameFail, err)
+	}
+	path, ok := pdfcache.File(game.ID, c.Param("name"))
+	if !ok {
+		return errcode.NotFound
+	}
+	c.File(path)
+	return nil
+}
+
+// loadVTTGame fetches the game, its cards and the requested theme
+func loadVTTGame(c *gin.Context) (model.Game, []model.Card, *theme.Theme, *errcode.Error) {
+	t, err := theme.Load(c.Query("theme"))
+	if err != nil {
+		return model.Game{}, nil, nil, themeError(c, errcode.ErrorExportGameFail, err)
+	}
+
+	game, cards, err := service.GetGameWithCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return game, nil, nil, gameError(c, errcode.ErrorExportGameFail, err)
+	}
+	return game, cards, t, nil
+}
+
+// storeCardBack renders the card back into the export cache and returns its URL
+func storeCardBack(fileURL func(path string) string, game model.Game, cards []model.Card, t *theme.Theme) (string, error) {
+	key, err := pdfcache.Key(game, cards, t.Name, t.Fingerprint(), "back", sheetCardWidth)
+	if err != nil {
+		return "", err
+	}
+	if path, ok := pdfcache.Lookup(game.ID, key, "_back.png"); ok {
+		return fileURL(path), nil
+	}
+
+	var buf bytes.Buffer
+	if err := cardimage.RenderBack(&buf, game.Theme, t, sheetCardWidth); err != nil {
+		return "", err
+	}
+	path, err := pdfcache.Store(game.ID, key, "_back.png", buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return fileURL(path), nil
+}
+
+// storeCardImages renders each card into the export cache and returns the
+// URLs by card ID
+func storeCardImages(fileURL func(path string) string, game model.Game, cards []model.Card, t *theme.Theme) (map[uint32]string, error) {
+	key, err := pdfcache.Key(game, cards, t.Name, t.Fingerprint(), "card", cardimage.DefaultWidth)
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[uint32]string, len(cards))
+	for _, card := range cards {
+		suffix := fmt.Sprintf("_card%d.png", card.ID)
+		path, ok := pdfcache.Lookup(game.ID, key, suffix)
+		if !ok {
+			var buf bytes.Buffer
+			if err := cardimage.Render(&buf, card, t, cardimage.FormatPNG, cardimage.DefaultWidth); err != nil {
+				return nil, err
+			}
+			if path, err = pdfcache.Store(game.ID, key, suffix, buf.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+		urls[card.ID] = fileURL(path)
+	}
+	return urls, nil
+}
+
+// storeCardSheets renders the 10 x 7 card sheets into the export cache and
+// returns their URLs in card order
+func storeCardSheets(fileURL func(path string) string, game model.Game, cards []model.Card, t *theme.Theme) ([]string, error) {
+	key, err := pdfcache.Key(game, cards, t.Name, t.Fingerprint(), "sheet", sheetCardWidth)
+	if err != nil {
+		return nil, err
+	}
+	count := (len(cards) + vtt.SheetSize - 1) / vtt.SheetSize
+	urls := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		path, ok := pdfcache.Lookup(game.ID, key, fmt.Sprintf("_sheet%d.png", i+1))
+		if !ok {
+			break
+		}
+		urls = append(urls, fileURL(path))
+	}
+	if len(urls) == count {
+		return urls, nil
+	}
+
+	sheets, err := cardimage.RenderSheets(cards, t, vtt.SheetColumns, vtt.SheetRows, sheetCardWidth)
+	if err != nil {
+		return nil, err
+	}
+	urls = urls[:0]
+	for i, sheet := range sheets {
+		var buf bytes.Buffer
+		if err := cardimage.EncodePNG(&buf, sheet); err != nil {
+			return nil, err
+		}
+		path, err := pdfcache.Store(game.ID, key, fmt.Sprintf("_sheet%d.png", i+1), buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, fileURL(path))
+	}
+	return urls, nil
+}
+
+// exportFileURL returns a function making the URL of an image of a game
+// stored in the export cache, served by GetExportFile. Tabletops download
+// the images without the user's token, so the URLs carry a share token.
+func exportFileURL(c *gin.Context, gameID uint32) (func(path string) string, error) {
+	token, _, err := app.GenerateShareToken(gameID, service.DefaultShareExpire)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign share token: %s", err)
+	}
+	return func(path string) string {
+		return publicURL(c, fmt.Sprintf("/api/v1/games/%d/export/files/%s?share=%s", gameID, filepath.Base(path), url.QueryEscape(token)))
+	}, nil
+}
+
+// publicURL makes path absolute using the scheme and host the client used,
+// since virtual tabletops download images from outside the browser
+func publicURL(c *gin.Context, path string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, path)
+}
@@ -1,66 +1,242 @@
 package routers
 
 import (
+	"context"
 	"net/http"
+	"os"
+	"path"
+	"strings"
 	"time"
 
 	_ "curly-succotash/backend/docs"
 	"curly-succotash/backend/global"
+	"curly-succotash/backend/internal/middleware"
+	"curly-succotash/backend/internal/model"
+	"curly-succotash/backend/internal/pdfcache"
+	"curly-succotash/backend/pkg/app"
 	"curly-succotash/backend/pkg/limiter"
+	"curly-succotash/backend/pkg/metrics"
+	"curly-succotash/backend/routers/api"
 	v1 "curly-succotash/backend/routers/api/v1"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-var methodLimiters = limiter.NewMethodLimiter().AddBuckets(
+// authLimiterRules limit the unauthenticated /auth and /readyz routes per
+// client IP
+var authLimiterRules = []limiter.LimiterBucketRule{
 	limiter.LimiterBucketRule{
 		Key:          "/auth",
 		FillInterval: time.Second,
 		Capacity:     10,
 		Quantum:      10,
 	},
-)
+	limiter.LimiterBucketRule{
+		Key:          "/auth/register",
+		FillInterval: time.Second,
+		Capacity:     10,
+		Quantum:      10,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/auth/login",
+		FillInterval: time.Second,
+		Capacity:     10,
+		Quantum:      10,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/auth/refresh",
+		FillInterval: time.Second,
+		Capacity:     10,
+		Quantum:      10,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/readyz",
+		FillInterval: time.Second,
+		Capacity:     10,
+		Quantum:      10,
+	},
+}
+
+// publicFiles serves the PDF folder without the export cache, which holds
+// exports of private games served by routes checking their visibility
+type publicFiles struct {
+	http.FileSystem
+}
+
+func (f publicFiles) Open(name string) (http.File, error) {
+	if name = path.Clean("/" + name); name == "/"+pdfcache.Dir || strings.HasPrefix(name, "/"+pdfcache.Dir+"/") {
+		return nil, os.ErrNotExist
+	}
+	return f.FileSystem.Open(name)
+}
+
+// apiLimiterRules limit every caller per route. Routes calling the AI or
+// rendering files get tighter buckets than the default rule.
+var apiLimiterRules = []limiter.LimiterBucketRule{
+	limiter.LimiterBucketRule{
+		Key:          limiter.DefaultRule,
+		FillInterval: time.Second,
+		Capacity:     120,
+		Quantum:      2,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/generate",
+		FillInterval: time.Minute,
+		Capacity:     5,
+		Quantum:      5,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/game",
+		FillInterval: time.Minute,
+		Capacity:     5,
+		Quantum:      5,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/games/:id/fork",
+		FillInterval: time.Minute,
+		Capacity:     5,
+		Quantum:      5,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/generate-pdf/:id",
+		FillInterval: time.Minute,
+		Capacity:     10,
+		Quantum:      10,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/games/:id/cards/images",
+		FillInterval: time.Minute,
+		Capacity:     10,
+		Quantum:      10,
+	},
+	limiter.LimiterBucketRule{
+		Key:          "/api/v1/games/:id/export/tts",
+		FillInterval: time.Minute,
+		Capacity:     10,
+		Quantum:      10,
+	},
+}
 
 func NewRouter() *gin.Engine {
 	r := gin.New()
+	if err := r.SetTrustedProxies(global.ServerSetting.Load().TrustedProxies); err != nil {
+		global.Logger.Errorf(context.Background(), "Failed to set trusted proxies: %s", err)
+	}
+	r.Use(otelgin.Middleware(global.TracingSetting.Load().ServiceName))
+	r.Use(middleware.Tracing())
+	r.Use(middleware.RequestLogger())
+	r.Use(middleware.Metrics())
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, traceparent")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "traceparent, X-Trace-ID, X-RateLimit-Limit, X-RateLimit-Remaining, Retry-After, X-Quota-Limit, X-Quota-Remaining")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 		c.Next()
 	})
-	if global.AppSetting.RunMode == "debug" {
-		r.Use(gin.Logger())
-		r.Use(gin.Recovery())
+	runMode := global.AppSetting.Load().RunMode
+	if runMode == "debug" {
+		r.Use(middleware.AccessLog())
 	}
-	if global.AppSetting.RunMode != "release" {
+	r.Use(middleware.Recovery())
+	r.NoRoute(middleware.NotFound)
+	if runMode != "release" {
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
-	// TODO: middlewares
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", app.Handle(api.Healthz))
+	limiterStore := newLimiterStore()
+	authLimiters := limiter.NewIdentityLimiter(limiterStore, middleware.LimiterIdentity).AddBuckets(authLimiterRules...)
+	apiLimiters := limiter.NewIdentityLimiter(limiterStore, middleware.LimiterIdentity).AddBuckets(apiLimiterRules...)
+	r.Use(middleware.RateLimiter(authLimiters))
+	r.GET("/readyz", app.Handle(api.Readyz))
 
-	r.Static("/files", "./files")
+	r.StaticFS("/files", publicFiles{gin.Dir(global.StoragePathSetting.Load().PDFFoldar, false)})
+
+	r.POST("/auth", middleware.Audit(model.AuditAuthToken), app.Handle(api.GetAuth))
+	r.POST("/auth/register", middleware.Audit(model.AuditAuthRegister), app.Handle(api.Register))
+	r.POST("/auth/login", middleware.Audit(model.AuditAuthLogin), app.Handle(api.Login))
+	r.POST("/auth/refresh", middleware.Audit(model.AuditAuthRefresh), app.Handle(api.Refresh))
 
 	generator := v1.NewGenerator()
 
+	read := middleware.Scope(model.ScopeGamesRead)
+	write := middleware.Scope(model.ScopeGamesWrite)
+	generate := middleware.Scope(model.ScopeGamesGenerate)
+	export := middleware.Scope(model.ScopeGamesExport)
+
+	// Read-only routes that also accept share link tokens
+	shared := r.Group("/api/v1")
+	shared.Use(middleware.JWTOrShare(), middleware.RateLimiter(apiLimiters))
+	{
+		shared.GET("/games/:id", read, app.Handle(v1.GetGame))
+		shared.GET("/games/:id/export/files/:name", read, app.Handle(v1.GetExportFile))
+		// TODO:
+		shared.GET("/generate-pdf/:id", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.GenerateHTMLPDF))
+	}
+
 	apiv1 := r.Group("/api/v1")
-	// TODO: JWT
-	apiv1.Use()
+	apiv1.Use(middleware.JWT(), middleware.RateLimiter(apiLimiters))
 	{
 		// Generate game
-		apiv1.POST("/generate", generator.Generate)
-		apiv1.POST("/game", v1.GenerateGame)
+		quota := middleware.GenerationQuota()
+		apiv1.POST("/generate", middleware.Audit(model.AuditGameGenerate), generate, quota, middleware.GenerationMetrics("generate"), app.Handle(generator.Generate))
+		apiv1.POST("/game", middleware.Audit(model.AuditGameGenerate), generate, quota, middleware.GenerationMetrics("game"), app.Handle(v1.GenerateGame))
 
-		apiv1.GET("/games", v1.ListGames)
-		apiv1.GET("/games/:id", v1.GetGame)
-		// TODO:
-		apiv1.GET("/generate-pdf/:id", v1.GenerateHTMLPDF)
+		apiv1.GET("/games", read, app.Handle(v1.ListGames))
+		apiv1.GET("/games/:id/cards/images", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.GetCardImages))
+		apiv1.GET("/games/:id/cards/:cardId/image", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.GetCardImage))
+		apiv1.POST("/games/import", middleware.Audit(model.AuditGameImport), write, app.Handle(v1.ImportGame))
+		apiv1.POST("/games/:id/fork", middleware.Audit(model.AuditGameFork), generate, app.Handle(v1.ForkGame))
+		apiv1.PUT("/games/:id/visibility", middleware.Audit(model.AuditGameUpdate), write, app.Handle(v1.SetVisibility))
+		apiv1.POST("/games/:id/share", middleware.Audit(model.AuditGameShare), write, app.Handle(v1.CreateShareLink))
+		apiv1.GET("/games/:id/revisions", read, app.Handle(v1.ListRevisions))
+		apiv1.GET("/games/:id/revisions/diff", read, app.Handle(v1.DiffRevisions))
+		apiv1.POST("/games/:id/revisions/:revisionId/rollback", middleware.Audit(model.AuditGameRollback), write, app.Handle(v1.RollbackGame))
+		apiv1.GET("/games/:id/export", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.ExportGame))
+		apiv1.GET("/games/:id/export/csv", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.ExportCardsCSV))
+		apiv1.GET("/games/:id/export/markdown", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.ExportCardsMarkdown))
+		apiv1.POST("/games/:id/cards/import", middleware.Audit(model.AuditCardsImport), write, app.Handle(v1.ImportCardsCSV))
+		apiv1.GET("/games/:id/export/tts", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.ExportTTS))
+		apiv1.GET("/games/:id/export/vtt", middleware.Audit(model.AuditGameExport), export, app.Handle(v1.ExportVTT))
+		apiv1.GET("/games/:id/usage", read, app.Handle(v1.GetGameUsage))
+		apiv1.GET("/usage", read, app.Handle(v1.GetUsage))
+		apiv1.GET("/themes", read, app.Handle(v1.ListThemes))
+
+		// AppKey management is limited to signed-in users
+		appkeys := apiv1.Group("/appkeys", middleware.UserOnly())
+		appkeys.POST("", middleware.Audit(model.AuditAppKeyCreate), app.Handle(v1.CreateAppKey))
+		appkeys.GET("", app.Handle(v1.ListAppKeys))
+		appkeys.POST("/:id/rotate", middleware.Audit(model.AuditAppKeyRotate), app.Handle(v1.RotateAppKey))
+		appkeys.DELETE("/:id", middleware.Audit(model.AuditAppKeyRevoke), app.Handle(v1.RevokeAppKey))
+	}
+
+	// Operational endpoints for admins listed in App.Admins
+	admin := r.Group("/admin")
+	admin.Use(middleware.JWT(), middleware.AdminOnly())
+	{
+		admin.GET("/debug", app.Handle(api.Debug))
+		admin.GET("/log-level", app.Handle(api.GetLogLevels))
+		admin.PUT("/log-level", middleware.Audit(model.AuditAdminLogLevel), app.Handle(api.SetLogLevels))
+		admin.GET("/audit", app.Handle(api.ListAuditLogs))
+		admin.GET("/audit/export/csv", app.Handle(api.ExportAuditLogs))
+		admin.GET("/usage", app.Handle(api.ListUsage))
 	}
 
 	return r
 }
+
+// newLimiterStore returns the rate limiter store selected by Limiter.Store.
+// The sql store shares buckets between replicas using the same database.
+func newLimiterStore() limiter.Store {
+	if global.LimiterSetting.Load().Store == "sql" {
+		return limiter.NewSQLStore(global.DBEngine)
+	}
+	return limiter.NewMemoryStore()
+}
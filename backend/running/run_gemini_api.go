@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"curly-succotash/backend/global"
 	"curly-succotash/backend/internal/ai"
 	"curly-succotash/backend/pkg/setting"
@@ -32,6 +33,7 @@ func setupSetting() error {
 }
 
 func main() {
+	ctx := context.Background()
 	aiClient, err := ai.NewGeminiClient()
 	if err != nil {
 		log.Fatalf("failed to initialize AI client: %s", err)
@@ -42,14 +44,14 @@ func main() {
 	log.Println("Gemini AI client initialized successfully")
 
 	prompt := fmt.Sprintf(global.StoryPromptTemplate, "Fantasy Adventure")
-	storyText, err := aiClient.GenerateContent(prompt)
+	storyText, err := aiClient.GenerateContent(ctx, prompt)
 	if err != nil {
 		log.Fatalf("failed to generate content: %s", err)
 		return
 	}
 
 	rolePrompt := fmt.Sprintf(global.RolePrompt, 1, storyText)
-	roleText, err := aiClient.GenerateContent(rolePrompt)
+	roleText, err := aiClient.GenerateContent(ctx, rolePrompt)
 	if err != nil {
 		log.Fatalf("failed to generate role text: %s", err)
 		return
@@ -57,7 +59,7 @@ func main() {
 	log.Printf("Generated role text: %s", roleText)
 
 	eventPrompt := fmt.Sprintf(global.EventPrompt, 1, storyText)
-	eventText, err := aiClient.GenerateContent(eventPrompt)
+	eventText, err := aiClient.GenerateContent(ctx, eventPrompt)
 	if err != nil {
 		log.Fatalf("failed to generate event text: %s", err)
 		return